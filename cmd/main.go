@@ -8,7 +8,9 @@ import (
 
 	"github.com/quiby-ai/review-preprocessor/config"
 	"github.com/quiby-ai/review-preprocessor/internal/consumer"
+	"github.com/quiby-ai/review-preprocessor/internal/lang"
 	"github.com/quiby-ai/review-preprocessor/internal/producer"
+	"github.com/quiby-ai/review-preprocessor/internal/profanity"
 	"github.com/quiby-ai/review-preprocessor/internal/service"
 	"github.com/quiby-ai/review-preprocessor/internal/storage"
 	"github.com/quiby-ai/review-preprocessor/internal/translate"
@@ -26,6 +28,10 @@ func main() {
 	db := storage.MustInitPostgres(cfg.Postgres)
 	repoRaw := storage.NewRawRepository(db)
 	repoClean := storage.NewCleanRepository(db)
+	repoSagas := storage.NewSagaRepository(db)
+	repoOptions := storage.NewOptionsRepository(db)
+	repoReports := storage.NewReportRepository(db)
+	repoFingerprints := storage.NewFingerprintRepository(db)
 
 	prod := producer.NewProducer(cfg.Kafka)
 
@@ -43,9 +49,31 @@ func main() {
 	default:
 		tr = translate.Noop{}
 	}
-	svc := service.NewPreprocessService(repoRaw, repoClean, prod, cfg.Processing, tr)
 
-	cons := consumer.NewKafkaConsumer(cfg.Kafka, svc)
+	var det lang.Detector
+	switch cfg.Processing.LangDetector {
+	case "ngram":
+		det = lang.NewNGramDetector()
+	case "ensemble":
+		w := cfg.Processing.LangDetectorWeights
+		det = lang.NewEnsemble(
+			lang.Weighted{Detector: lang.WhatlangDetector{}, Weight: w["whatlang"]},
+			lang.Weighted{Detector: lang.NewNGramDetector(), Weight: w["ngram"]},
+		)
+	default:
+		det = lang.WhatlangDetector{}
+	}
+
+	var prof *profanity.Filter
+	if cfg.Processing.ProfanityEnabled {
+		prof, err = profanity.Load(cfg.Processing.ProfanityLexiconDir)
+		if err != nil {
+			log.Fatalf("profanity lexicons: %v", err)
+		}
+	}
+	svc := service.NewPreprocessService(repoRaw, repoClean, repoSagas, repoOptions, repoReports, repoFingerprints, prod, cfg.Processing, tr, det, prof)
+
+	cons := consumer.NewKafkaConsumer(cfg.Kafka, svc, prod)
 	if err := cons.Run(ctx); err != nil {
 		log.Fatalf("consumer exited with error: %v", err)
 	}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quiby-ai/review-preprocessor/internal/dedup"
+	"github.com/quiby-ai/review-preprocessor/internal/textutil"
 	"github.com/spf13/viper"
 )
 
@@ -17,6 +19,11 @@ type Config struct {
 type KafkaConfig struct {
 	Brokers []string
 	GroupID string
+
+	// failure handling
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	DeadLetterTopic string
 }
 
 type PostgresConfig struct {
@@ -24,29 +31,68 @@ type PostgresConfig struct {
 }
 
 type ProcessingConfig struct {
+	// PipelineVersion is stored with every clean review; bump it whenever cleaning
+	// or translation output changes so unchanged rows get reprocessed.
+	PipelineVersion     string
 	DefaultLang         string
 	BatchSize           int
 	PublishIDsLimit     int
 	MaxReviewLen        int
 	MinContentLen       int
 	HTMLStrip           bool
-	EmojiStrip          bool
+	Normalize           textutil.NormalizeOptions
+	EmojiMode           textutil.EmojiMode
 	WhitespaceNormalize bool
+	PIIRedact           bool
+	PIIDetectors        []string
+	Pipeline            []textutil.StageSpec
 	TimeoutPerBatch     time.Duration
+	SagaStaleAfter      time.Duration
 
 	// contentfulness thresholds
 	MinWords      int
 	MinChars      int
 	MinAlphaRatio float64
+	SpamThreshold float64
 	SaveSkipped   bool
 
+	// profanity
+	ProfanityEnabled    bool
+	ProfanityMask       bool
+	ProfanityLexiconDir string
+
+	// near-duplicate detection
+	DedupEnabled          bool
+	DedupMaxDistance      int
+	DedupExcludeFromCount bool
+
+	// sentiment
+	SentimentEnabled           bool
+	SentimentMismatchThreshold float64
+
 	// language detection / translation
-	LangDetectMinConf   float64
-	TranslateEnabled    bool
-	TranslateTargetLang string
-	TranslateBatchSize  int
-	TranslateTimeout    time.Duration
-	TranslateProvider   string
+	LangDetector         string
+	LangDetectorWeights  map[string]float64
+	LangDetectMinConf    float64
+	LangDetectTitleBelow int
+	// LangMixMinConf is the confidence a single sentence needs to count as
+	// its detected language in the language mix; single sentences are
+	// rarely detected as confidently as whole reviews.
+	LangMixMinConf float64
+	// TranslateMixThreshold is the share of non-English text from which a
+	// review is translated; 0 decides on the single detected language.
+	TranslateMixThreshold float64
+	TranslateEnabled      bool
+	TranslateTargetLang   string
+	// TranslateResponses also translates developer responses. Turning it on
+	// needs no pipeline_version bump: stored rows missing a response
+	// translation are not reused, so they are backfilled on the next run.
+	TranslateResponses bool
+	TranslateBatchSize int
+	// TranslateConcurrency bounds how many sub-batches are in flight at once.
+	TranslateConcurrency int
+	TranslateTimeout     time.Duration
+	TranslateProvider    string
 
 	// translation fallback
 	TranslateFallbackEnabled       bool
@@ -78,30 +124,53 @@ func Load() (*Config, error) {
 		Kafka: KafkaConfig{
 			Brokers: viper.GetStringSlice("kafka.brokers"),
 			GroupID: viper.GetString("kafka.group_id"),
+
+			MaxAttempts:     viper.GetInt("kafka.max_attempts"),
+			DeadLetterTopic: viper.GetString("kafka.dead_letter_topic"),
 		},
 		Postgres: PostgresConfig{
 			DSN: viper.GetString("PG_DSN"),
 		},
 		Processing: ProcessingConfig{
+			PipelineVersion:     viper.GetString("processing.pipeline_version"),
 			DefaultLang:         viper.GetString("processing.default_lang"),
 			BatchSize:           viper.GetInt("processing.batch_size"),
 			PublishIDsLimit:     viper.GetInt("processing.publish_ids_limit"),
 			MaxReviewLen:        viper.GetInt("processing.max_review_len"),
 			MinContentLen:       viper.GetInt("processing.min_content_len"),
 			HTMLStrip:           viper.GetBool("processing.html_strip"),
-			EmojiStrip:          viper.GetBool("processing.emoji_strip"),
 			WhitespaceNormalize: viper.GetBool("processing.whitespace_normalize"),
+			PIIRedact:           viper.GetBool("processing.pii_redact"),
+			PIIDetectors:        viper.GetStringSlice("processing.pii_detectors"),
 
 			MinWords:      viper.GetInt("processing.min_words"),
 			MinChars:      viper.GetInt("processing.min_chars"),
 			MinAlphaRatio: viper.GetFloat64("processing.min_alpha_ratio"),
+			SpamThreshold: viper.GetFloat64("processing.spam_threshold"),
 			SaveSkipped:   viper.GetBool("processing.save_skipped"),
 
-			LangDetectMinConf:   viper.GetFloat64("processing.lang_detect_min_conf"),
-			TranslateEnabled:    viper.GetBool("processing.translate_enabled"),
-			TranslateTargetLang: viper.GetString("processing.translate_target_lang"),
-			TranslateBatchSize:  viper.GetInt("processing.translate_batch_size"),
-			TranslateProvider:   viper.GetString("processing.translate_provider"),
+			ProfanityEnabled:    viper.GetBool("processing.profanity_enabled"),
+			ProfanityMask:       viper.GetBool("processing.profanity_mask"),
+			ProfanityLexiconDir: viper.GetString("processing.profanity_lexicon_dir"),
+
+			DedupEnabled:          viper.GetBool("processing.dedup_enabled"),
+			DedupMaxDistance:      viper.GetInt("processing.dedup_max_distance"),
+			DedupExcludeFromCount: viper.GetBool("processing.dedup_exclude_from_count"),
+
+			SentimentEnabled:           viper.GetBool("processing.sentiment_enabled"),
+			SentimentMismatchThreshold: viper.GetFloat64("processing.sentiment_mismatch_threshold"),
+
+			LangDetector:          viper.GetString("processing.lang_detector"),
+			LangDetectMinConf:     viper.GetFloat64("processing.lang_detect_min_conf"),
+			LangDetectTitleBelow:  viper.GetInt("processing.lang_detect_title_below"),
+			LangMixMinConf:        viper.GetFloat64("processing.lang_mix_min_conf"),
+			TranslateMixThreshold: viper.GetFloat64("processing.translate_mix_threshold"),
+			TranslateEnabled:      viper.GetBool("processing.translate_enabled"),
+			TranslateTargetLang:   viper.GetString("processing.translate_target_lang"),
+			TranslateResponses:    viper.GetBool("processing.translate_responses"),
+			TranslateBatchSize:    viper.GetInt("processing.translate_batch_size"),
+			TranslateConcurrency:  viper.GetInt("processing.translate_concurrency"),
+			TranslateProvider:     viper.GetString("processing.translate_provider"),
 
 			TranslateFallbackEnabled:       viper.GetBool("processing.translate_fallback_enabled"),
 			TranslateFallbackModel:         viper.GetString("processing.translate_fallback_model"),
@@ -115,8 +184,81 @@ func Load() (*Config, error) {
 		},
 	}
 
+	if config.Kafka.MaxAttempts <= 0 {
+		config.Kafka.MaxAttempts = 3
+	}
+	if config.Kafka.DeadLetterTopic == "" {
+		config.Kafka.DeadLetterTopic = "pipeline.prepare_reviews.dlq"
+	}
+	config.Kafka.RetryBackoff = time.Duration(viper.GetInt("kafka.retry_backoff_seconds")) * time.Second
+
+	// emoji_strip predates emoji_mode and is still honoured when the latter is unset
+	emojiMode := viper.GetString("processing.emoji_mode")
+	if emojiMode == "" && viper.GetBool("processing.emoji_strip") {
+		emojiMode = string(textutil.EmojiStrip)
+	}
+	mode, err := textutil.ParseEmojiMode(emojiMode)
+	if err != nil {
+		return nil, fmt.Errorf("processing.emoji_mode: %w", err)
+	}
+	config.Processing.EmojiMode = mode
+
+	form, err := textutil.ParseNormForm(viper.GetString("processing.normalize_form"))
+	if err != nil {
+		return nil, fmt.Errorf("processing.normalize_form: %w", err)
+	}
+	config.Processing.Normalize = textutil.NormalizeOptions{
+		Form:            form,
+		StripInvisible:  viper.GetBool("processing.strip_invisible"),
+		FoldConfusables: viper.GetBool("processing.fold_confusables"),
+	}
+
+	// An explicit [[processing.pipeline]] replaces the flat cleaning flags
+	// (html_strip, normalize_*, emoji_mode, pii_*, whitespace_normalize,
+	// max_review_len), which are kept for older config files.
+	pipeline, err := loadPipeline(viper.Get("processing.pipeline"))
+	if err != nil {
+		return nil, fmt.Errorf("processing.pipeline: %w", err)
+	}
+	if len(pipeline) == 0 {
+		pipeline = legacyPipeline(config.Processing)
+	}
+	if _, err := textutil.BuildPipeline(pipeline); err != nil {
+		return nil, fmt.Errorf("processing.pipeline: %w", err)
+	}
+	config.Processing.Pipeline = pipeline
+	config.Processing.syncPipelineFlags()
+
+	if d := config.Processing.DedupMaxDistance; d < 0 || d > dedup.MaxDistance {
+		return nil, fmt.Errorf("processing.dedup_max_distance must be within [0,%d], got %d", dedup.MaxDistance, d)
+	}
+
+	if err := config.Processing.loadLangDetector(viper.GetStringMap("processing.lang_detector_weights")); err != nil {
+		return nil, err
+	}
+
+	if c := config.Processing.LangMixMinConf; c < 0 || c > 1 {
+		return nil, fmt.Errorf("processing.lang_mix_min_conf must be within [0,1], got %v", c)
+	}
+	if t := config.Processing.TranslateMixThreshold; t < 0 || t > 1 {
+		return nil, fmt.Errorf("processing.translate_mix_threshold must be within [0,1], got %v", t)
+	}
+	if t := config.Processing.SentimentMismatchThreshold; t < 0 || t > 1 {
+		return nil, fmt.Errorf("processing.sentiment_mismatch_threshold must be within [0,1], got %v", t)
+	}
+
+	if config.Processing.PipelineVersion == "" {
+		config.Processing.PipelineVersion = "1"
+	}
+
 	// seconds → durations mapping for convenience
 	config.Processing.TimeoutPerBatch = time.Duration(viper.GetInt("processing.timeout_seconds")) * time.Second
+	// a running saga older than this is assumed dead and may be taken over
+	if v := viper.GetInt("processing.saga_stale_after_seconds"); v > 0 {
+		config.Processing.SagaStaleAfter = time.Duration(v) * time.Second
+	} else {
+		config.Processing.SagaStaleAfter = time.Hour
+	}
 	// translate timeout in seconds
 	if v := viper.GetInt("processing.translate_timeout_seconds"); v > 0 {
 		config.Processing.TranslateTimeout = time.Duration(v) * time.Second
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// langDetectors are the detectors processing.lang_detector can name besides
+// "ensemble", which combines them by processing.lang_detector_weights.
+var langDetectors = []string{"whatlang", "ngram"}
+
+// loadLangDetector validates the detector choice and decodes its weights.
+func (c *ProcessingConfig) loadLangDetector(raw map[string]any) error {
+	if c.LangDetector == "" {
+		c.LangDetector = "whatlang"
+	}
+	c.LangDetectorWeights = make(map[string]float64, len(raw))
+	var total float64
+	for name, v := range raw {
+		if !slices.Contains(langDetectors, name) {
+			return fmt.Errorf("processing.lang_detector_weights: unknown detector %q", name)
+		}
+		var w float64
+		switch n := v.(type) {
+		case int64:
+			w = float64(n)
+		case int:
+			w = float64(n)
+		case float64:
+			w = n
+		default:
+			return fmt.Errorf("processing.lang_detector_weights.%s must be a number, got %T", name, v)
+		}
+		if w < 0 {
+			return fmt.Errorf("processing.lang_detector_weights.%s must be >= 0, got %v", name, w)
+		}
+		c.LangDetectorWeights[name] = w
+		total += w
+	}
+	switch {
+	case c.LangDetector == "ensemble":
+		if total == 0 {
+			return fmt.Errorf("processing.lang_detector_weights: ensemble needs a positive weight")
+		}
+	case !slices.Contains(langDetectors, c.LangDetector):
+		return fmt.Errorf("processing.lang_detector: unknown detector %q", c.LangDetector)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ProcessingOptions are per-app overrides of ProcessingConfig. Nil fields keep
+// the global value.
+type ProcessingOptions struct {
+	MinContentLen         *int     `json:"min_content_len,omitempty"`
+	MaxReviewLen          *int     `json:"max_review_len,omitempty"`
+	HTMLStrip             *bool    `json:"html_strip,omitempty"`
+	WhitespaceNormalize   *bool    `json:"whitespace_normalize,omitempty"`
+	MinWords              *int     `json:"min_words,omitempty"`
+	MinChars              *int     `json:"min_chars,omitempty"`
+	MinAlphaRatio         *float64 `json:"min_alpha_ratio,omitempty"`
+	SpamThreshold         *float64 `json:"spam_threshold,omitempty"`
+	SaveSkipped           *bool    `json:"save_skipped,omitempty"`
+	DefaultLang           *string  `json:"default_lang,omitempty"`
+	LangDetectMinConf     *float64 `json:"lang_detect_min_conf,omitempty"`
+	LangMixMinConf        *float64 `json:"lang_mix_min_conf,omitempty"`
+	TranslateEnabled      *bool    `json:"translate_enabled,omitempty"`
+	TranslateMixThreshold *float64 `json:"translate_mix_threshold,omitempty"`
+}
+
+// Validate reports every out-of-range override at once.
+func (o ProcessingOptions) Validate() error {
+	var errs []error
+	nonNegative := func(name string, v *int) {
+		if v != nil && *v < 0 {
+			errs = append(errs, fmt.Errorf("%s must be >= 0, got %d", name, *v))
+		}
+	}
+	unit := func(name string, v *float64) {
+		if v != nil && (*v < 0 || *v > 1) {
+			errs = append(errs, fmt.Errorf("%s must be within [0,1], got %g", name, *v))
+		}
+	}
+	nonNegative("min_content_len", o.MinContentLen)
+	nonNegative("max_review_len", o.MaxReviewLen)
+	nonNegative("min_words", o.MinWords)
+	nonNegative("min_chars", o.MinChars)
+	unit("min_alpha_ratio", o.MinAlphaRatio)
+	unit("spam_threshold", o.SpamThreshold)
+	unit("lang_detect_min_conf", o.LangDetectMinConf)
+	unit("lang_mix_min_conf", o.LangMixMinConf)
+	unit("translate_mix_threshold", o.TranslateMixThreshold)
+	if o.DefaultLang != nil && (len(*o.DefaultLang) < 2 || len(*o.DefaultLang) > 8) {
+		errs = append(errs, fmt.Errorf("default_lang must be a language code, got %q", *o.DefaultLang))
+	}
+	if o.MaxReviewLen != nil && o.MinContentLen != nil && *o.MaxReviewLen > 0 && *o.MinContentLen > *o.MaxReviewLen {
+		errs = append(errs, fmt.Errorf("min_content_len %d exceeds max_review_len %d", *o.MinContentLen, *o.MaxReviewLen))
+	}
+	return errors.Join(errs...)
+}
+
+// Apply returns cfg with the overrides merged over it. When any override is
+// set, PipelineVersion gets a suffix derived from them so reviews cleaned with
+// different options are never reused for one another.
+func (o ProcessingOptions) Apply(cfg ProcessingConfig) ProcessingConfig {
+	setInt(&cfg.MinContentLen, o.MinContentLen)
+	setInt(&cfg.MaxReviewLen, o.MaxReviewLen)
+	setBool(&cfg.HTMLStrip, o.HTMLStrip)
+	setBool(&cfg.WhitespaceNormalize, o.WhitespaceNormalize)
+	setInt(&cfg.MinWords, o.MinWords)
+	setInt(&cfg.MinChars, o.MinChars)
+	setFloat(&cfg.MinAlphaRatio, o.MinAlphaRatio)
+	setFloat(&cfg.SpamThreshold, o.SpamThreshold)
+	setBool(&cfg.SaveSkipped, o.SaveSkipped)
+	if o.DefaultLang != nil {
+		cfg.DefaultLang = *o.DefaultLang
+	}
+	setFloat(&cfg.LangDetectMinConf, o.LangDetectMinConf)
+	setFloat(&cfg.LangMixMinConf, o.LangMixMinConf)
+	setBool(&cfg.TranslateEnabled, o.TranslateEnabled)
+	setFloat(&cfg.TranslateMixThreshold, o.TranslateMixThreshold)
+	cfg.Pipeline = o.applyStages(cfg.Pipeline)
+
+	if b, _ := json.Marshal(o); string(b) != "{}" {
+		sum := sha256.Sum256(b)
+		cfg.PipelineVersion += "+" + hex.EncodeToString(sum[:4])
+	}
+	return cfg
+}
+
+// OptionsOf returns the full set of overridable values in effect for cfg.
+func OptionsOf(cfg ProcessingConfig) ProcessingOptions {
+	return ProcessingOptions{
+		MinContentLen:         &cfg.MinContentLen,
+		MaxReviewLen:          &cfg.MaxReviewLen,
+		HTMLStrip:             &cfg.HTMLStrip,
+		WhitespaceNormalize:   &cfg.WhitespaceNormalize,
+		MinWords:              &cfg.MinWords,
+		MinChars:              &cfg.MinChars,
+		MinAlphaRatio:         &cfg.MinAlphaRatio,
+		SpamThreshold:         &cfg.SpamThreshold,
+		SaveSkipped:           &cfg.SaveSkipped,
+		DefaultLang:           &cfg.DefaultLang,
+		LangDetectMinConf:     &cfg.LangDetectMinConf,
+		LangMixMinConf:        &cfg.LangMixMinConf,
+		TranslateEnabled:      &cfg.TranslateEnabled,
+		TranslateMixThreshold: &cfg.TranslateMixThreshold,
+	}
+}
+
+func setInt(dst *int, v *int) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setFloat(dst *float64, v *float64) {
+	if v != nil {
+		*dst = *v
+	}
+}
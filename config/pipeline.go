@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/quiby-ai/review-preprocessor/internal/textutil"
+)
+
+// loadPipeline decodes [[processing.pipeline]]. Each table names its stage
+// with `stage`; the remaining keys are that stage's parameters.
+func loadPipeline(raw any) ([]textutil.StageSpec, error) {
+	var tables []map[string]any
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case []map[string]any:
+		tables = v
+	case []any:
+		for i, t := range v {
+			m, ok := t.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("entry %d is not a table", i)
+			}
+			tables = append(tables, m)
+		}
+	default:
+		return nil, fmt.Errorf("must be an array of tables, got %T", raw)
+	}
+
+	specs := make([]textutil.StageSpec, 0, len(tables))
+	for i, t := range tables {
+		name, _ := t["stage"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("entry %d has no stage name", i)
+		}
+		params := make(map[string]any, len(t))
+		for k, v := range t {
+			if k != "stage" {
+				params[k] = v
+			}
+		}
+		specs = append(specs, textutil.StageSpec{Name: name, Params: params})
+	}
+	return specs, nil
+}
+
+// legacyPipeline derives the stage list from the flat cleaning flags used
+// before [[processing.pipeline]] existed.
+func legacyPipeline(c ProcessingConfig) []textutil.StageSpec {
+	var specs []textutil.StageSpec
+	if c.HTMLStrip {
+		specs = append(specs, textutil.StageSpec{Name: textutil.StageHTML})
+	}
+	specs = append(specs, textutil.StageSpec{Name: textutil.StageNormalize, Params: map[string]any{
+		"form":             string(c.Normalize.Form),
+		"strip_invisible":  c.Normalize.StripInvisible,
+		"fold_confusables": c.Normalize.FoldConfusables,
+	}})
+	if c.EmojiMode != textutil.EmojiKeep {
+		specs = append(specs, textutil.StageSpec{Name: textutil.StageEmoji, Params: map[string]any{"mode": string(c.EmojiMode)}})
+	}
+	if c.PIIRedact {
+		specs = append(specs, textutil.StageSpec{Name: textutil.StagePII, Params: map[string]any{"detectors": c.PIIDetectors}})
+	}
+	if c.WhitespaceNormalize {
+		specs = append(specs, textutil.StageSpec{Name: textutil.StageWhitespace})
+	}
+	if c.MaxReviewLen > 0 {
+		specs = append(specs, textutil.StageSpec{Name: textutil.StageTruncate, Params: map[string]any{"max_len": c.MaxReviewLen}})
+	}
+	return specs
+}
+
+// syncPipelineFlags points the flat flags that per-app options can override
+// at what the configured pipeline actually does, so OptionsOf reports it.
+func (c *ProcessingConfig) syncPipelineFlags() {
+	c.HTMLStrip = hasStage(c.Pipeline, textutil.StageHTML)
+	c.WhitespaceNormalize = hasStage(c.Pipeline, textutil.StageWhitespace)
+	c.MaxReviewLen = 0
+	for _, s := range c.Pipeline {
+		if s.Name == textutil.StageTruncate {
+			// the pipeline was built successfully, so max_len is well-typed
+			c.MaxReviewLen, _ = s.Int("max_len", 0)
+		}
+	}
+}
+
+func hasStage(specs []textutil.StageSpec, name string) bool {
+	return slices.ContainsFunc(specs, func(s textutil.StageSpec) bool { return s.Name == name })
+}
+
+// applyStages carries the overrides that concern cleaning into the stage
+// list: html and whitespace stages are dropped or added, and max_review_len
+// becomes the max_len of every truncate stage.
+func (o ProcessingOptions) applyStages(specs []textutil.StageSpec) []textutil.StageSpec {
+	out := make([]textutil.StageSpec, 0, len(specs)+3)
+	for _, s := range specs {
+		switch {
+		case s.Name == textutil.StageHTML && o.HTMLStrip != nil && !*o.HTMLStrip:
+			continue
+		case s.Name == textutil.StageWhitespace && o.WhitespaceNormalize != nil && !*o.WhitespaceNormalize:
+			continue
+		case s.Name == textutil.StageTruncate && o.MaxReviewLen != nil:
+			s = s.WithParam("max_len", *o.MaxReviewLen)
+		}
+		out = append(out, s)
+	}
+	if o.HTMLStrip != nil && *o.HTMLStrip && !hasStage(out, textutil.StageHTML) {
+		out = slices.Insert(out, 0, textutil.StageSpec{Name: textutil.StageHTML})
+	}
+	if o.WhitespaceNormalize != nil && *o.WhitespaceNormalize && !hasStage(out, textutil.StageWhitespace) {
+		// whitespace has to be collapsed before truncation measures the text
+		at := slices.IndexFunc(out, func(s textutil.StageSpec) bool { return s.Name == textutil.StageTruncate })
+		if at < 0 {
+			at = len(out)
+		}
+		out = slices.Insert(out, at, textutil.StageSpec{Name: textutil.StageWhitespace})
+	}
+	if o.MaxReviewLen != nil && *o.MaxReviewLen > 0 && !hasStage(out, textutil.StageTruncate) {
+		out = append(out, textutil.StageSpec{Name: textutil.StageTruncate, Params: map[string]any{"max_len": *o.MaxReviewLen}})
+	}
+	return out
+}
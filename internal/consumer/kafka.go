@@ -2,31 +2,123 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/quiby-ai/common/pkg/events"
 	"github.com/quiby-ai/review-preprocessor/config"
+	"github.com/quiby-ai/review-preprocessor/internal/producer"
 	"github.com/quiby-ai/review-preprocessor/internal/service"
 )
 
+// preprocessor runs a prepare request; *service.PreprocessService implements it.
+type preprocessor interface {
+	Handle(ctx context.Context, evt events.PrepareRequest, sagaID string) error
+}
+
+// publisher builds and publishes outgoing events; *producer.Producer implements it.
+type publisher interface {
+	PublishEvent(ctx context.Context, key []byte, envelope events.Envelope[any]) error
+	BuildFailedEnvelope(event producer.PrepareFailed, sagaID, appID string) events.Envelope[any]
+	BuildDeadLetterEnvelope(topic string, dl producer.DeadLetter) events.Envelope[any]
+}
+
 type PreprocessServiceProcessor struct {
-	svc *service.PreprocessService
+	svc  preprocessor
+	prod publisher
+	cfg  config.KafkaConfig
 }
 
+// Handle runs the service, retrying retryable failures up to MaxAttempts times.
+// Once a request gives up, a failure event is published for the orchestrator and
+// the request is parked on the dead-letter topic.
 func (p *PreprocessServiceProcessor) Handle(ctx context.Context, payload any, sagaID string) error {
-	if evt, ok := payload.(events.PrepareRequest); ok {
-		return p.svc.Handle(ctx, evt, sagaID)
+	evt, ok := payload.(events.PrepareRequest)
+	if !ok {
+		return fmt.Errorf("invalid payload type for preprocess service")
+	}
+	for attempt := 1; ; attempt++ {
+		err := p.svc.Handle(ctx, evt, sagaID)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, service.ErrSagaInProgress) {
+			log.Printf("saga %s is already being processed, dropping duplicate", sagaID)
+			return nil
+		}
+		code, retryable := service.Classify(err)
+		if ctx.Err() != nil {
+			// Shutting down. The consumer committed the offset when it read the
+			// message, so it will not be redelivered; report an interruption by
+			// the shutdown as retryable so the orchestrator can request the saga
+			// again. Failures that are permanent on their own stay permanent.
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				retryable = true
+			}
+			p.fail(context.WithoutCancel(ctx), evt, sagaID, err, code, retryable, attempt)
+			return err
+		}
+		if retryable && attempt < p.cfg.MaxAttempts {
+			log.Printf("saga %s attempt %d/%d failed (%s): %v", sagaID, attempt, p.cfg.MaxAttempts, code, err)
+			if !sleep(ctx, p.cfg.RetryBackoff*time.Duration(attempt)) {
+				return err
+			}
+			continue
+		}
+		p.fail(ctx, evt, sagaID, err, code, retryable, attempt)
+		return err
+	}
+}
+
+func (p *PreprocessServiceProcessor) fail(ctx context.Context, evt events.PrepareRequest, sagaID string, cause error, code events.FailedCode, retryable bool, attempts int) {
+	failed := producer.NewPrepareFailed(code, retryable, cause.Error(), attempts)
+	if err := failed.Validate(); err != nil {
+		log.Printf("prepare failed event for saga %s is invalid: %v", sagaID, err)
+	} else if err := p.prod.PublishEvent(ctx, []byte(sagaID), p.prod.BuildFailedEnvelope(failed, sagaID, evt.AppID)); err != nil {
+		log.Printf("publish prepare failed for saga %s: %v", sagaID, err)
+	}
+
+	// The shared consumer only hands over the decoded payload, so the original
+	// request envelope is reconstructed from it.
+	original := events.NewEnvelope(sagaID, events.PipelinePrepareRequest, evt, events.NewMeta(evt.AppID, events.InitiatorSystem))
+	original.Meta.Retries = attempts - 1
+	dl := producer.DeadLetter{
+		Envelope:  original,
+		Code:      code,
+		Retryable: retryable,
+		Error:     cause.Error(),
+		Attempts:  attempts,
+		FailedAt:  time.Now().UTC(),
+	}
+	if err := p.prod.PublishEvent(ctx, []byte(sagaID), p.prod.BuildDeadLetterEnvelope(p.cfg.DeadLetterTopic, dl)); err != nil {
+		log.Printf("publish dead letter for saga %s: %v", sagaID, err)
+	}
+}
+
+// sleep waits for d or until ctx is done, reporting whether the full wait elapsed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
 	}
-	return fmt.Errorf("invalid payload type for preprocess service")
 }
 
 type KafkaConsumer struct {
 	consumer *events.KafkaConsumer
 }
 
-func NewKafkaConsumer(cfg config.KafkaConfig, svc *service.PreprocessService) *KafkaConsumer {
+func NewKafkaConsumer(cfg config.KafkaConfig, svc *service.PreprocessService, prod *producer.Producer) *KafkaConsumer {
 	consumer := events.NewKafkaConsumer(cfg.Brokers, events.PipelinePrepareRequest, cfg.GroupID)
-	processor := &PreprocessServiceProcessor{svc: svc}
+	processor := &PreprocessServiceProcessor{svc: svc, prod: prod, cfg: cfg}
 	consumer.SetProcessor(processor)
 	return &KafkaConsumer{consumer: consumer}
 }
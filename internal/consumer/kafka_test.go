@@ -0,0 +1,108 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/quiby-ai/common/pkg/events"
+	"github.com/quiby-ai/review-preprocessor/config"
+	"github.com/quiby-ai/review-preprocessor/internal/producer"
+	"github.com/quiby-ai/review-preprocessor/internal/service"
+)
+
+// stubPreprocessor returns errs in turn, then nil. When cancel is set it is
+// called before the first error is returned, as on a shutdown mid-request.
+type stubPreprocessor struct {
+	errs   []error
+	calls  int
+	cancel context.CancelFunc
+}
+
+func (s *stubPreprocessor) Handle(ctx context.Context, evt events.PrepareRequest, sagaID string) error {
+	s.calls++
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.calls > len(s.errs) {
+		return nil
+	}
+	return s.errs[s.calls-1]
+}
+
+// recordingPublisher keeps published envelopes instead of sending them.
+type recordingPublisher struct {
+	*producer.Producer
+	sent []events.Envelope[any]
+}
+
+func (r *recordingPublisher) PublishEvent(ctx context.Context, key []byte, envelope events.Envelope[any]) error {
+	r.sent = append(r.sent, envelope)
+	return nil
+}
+
+func TestHandle(t *testing.T) {
+	permanent := &service.Error{Code: events.FailedCodeUnknown, Retryable: false, Err: errors.New("bad request")}
+	transient := errors.New("connection reset")
+	tests := []struct {
+		name          string
+		errs          []error
+		cancel        bool
+		wantCalls     int
+		wantFailed    bool
+		wantRetryable bool
+		wantAttempts  int
+	}{
+		{"success", nil, false, 1, false, false, 0},
+		{"retryable failure recovers", []error{transient, transient}, false, 3, false, false, 0},
+		{"retryable failure gives up", []error{transient, transient, transient}, false, 3, true, true, 3},
+		{"permanent failure is not retried", []error{permanent}, false, 1, true, false, 1},
+		{"duplicate saga is dropped", []error{service.ErrSagaInProgress}, false, 1, false, false, 0},
+		{"shutdown interruption is retryable", []error{fmt.Errorf("load reviews: %w", context.Canceled)}, true, 1, true, true, 1},
+		{"permanent failure during shutdown stays permanent", []error{permanent}, true, 1, true, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			svc := &stubPreprocessor{errs: tt.errs}
+			if tt.cancel {
+				svc.cancel = cancel
+			}
+			pub := &recordingPublisher{Producer: &producer.Producer{}}
+			p := &PreprocessServiceProcessor{svc: svc, prod: pub, cfg: config.KafkaConfig{MaxAttempts: 3, DeadLetterTopic: "dlq"}}
+
+			err := p.Handle(ctx, events.PrepareRequest{ExtractRequest: events.ExtractRequest{AppID: "app"}}, "saga-1")
+			if svc.calls != tt.wantCalls {
+				t.Errorf("service called %d times, want %d", svc.calls, tt.wantCalls)
+			}
+			if !tt.wantFailed {
+				if err != nil || len(pub.sent) != 0 {
+					t.Fatalf("Handle() = %v with %d events published, want success", err, len(pub.sent))
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Handle() = nil, want error")
+			}
+			if len(pub.sent) != 2 {
+				t.Fatalf("published %d events, want failure and dead letter", len(pub.sent))
+			}
+			failed, ok := pub.sent[0].Payload.(producer.PrepareFailed)
+			if !ok {
+				t.Fatalf("first event payload is %T, want PrepareFailed", pub.sent[0].Payload)
+			}
+			if failed.Retryable != tt.wantRetryable || failed.Attempts != tt.wantAttempts {
+				t.Errorf("failure retryable=%v attempts=%d, want %v and %d", failed.Retryable, failed.Attempts, tt.wantRetryable, tt.wantAttempts)
+			}
+			dl, ok := pub.sent[1].Payload.(producer.DeadLetter)
+			if !ok {
+				t.Fatalf("second event payload is %T, want DeadLetter", pub.sent[1].Payload)
+			}
+			if dl.Retryable != tt.wantRetryable || pub.sent[1].Type != "dlq" {
+				t.Errorf("dead letter retryable=%v type=%q, want %v and %q", dl.Retryable, pub.sent[1].Type, tt.wantRetryable, "dlq")
+			}
+		})
+	}
+}
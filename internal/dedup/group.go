@@ -0,0 +1,60 @@
+package dedup
+
+import "github.com/google/uuid"
+
+// Entry is a fingerprinted review and the duplicate group it belongs to.
+// GroupID and CanonicalID are empty for reviews without near-duplicates; the
+// canonical review of a group is its earliest member.
+type Entry struct {
+	ReviewID    string
+	Fingerprint Fingerprint
+	GroupID     string
+	CanonicalID string
+}
+
+// Duplicate reports whether e is a non-canonical member of a group.
+func (e Entry) Duplicate() bool {
+	return e.CanonicalID != "" && e.CanonicalID != e.ReviewID
+}
+
+// Assign matches items, in order, against history and the items before them
+// and sets each item's group from its nearest match within maxDistance bits.
+// A match that had no group yet starts one with itself as canonical review;
+// Assign returns the history entries that changed that way so callers can
+// persist them. An item is never matched against its own history entry.
+func Assign(items []Entry, history []Entry, maxDistance int) (changed []Entry) {
+	known := make([]*Entry, 0, len(history)+len(items))
+	for i := range history {
+		known = append(known, &history[i])
+	}
+	fromHistory := len(history)
+	started := map[int]bool{}
+	for i := range items {
+		it := &items[i]
+		it.GroupID, it.CanonicalID = "", ""
+		best, bestDist := -1, maxDistance+1
+		for k, e := range known {
+			if e.ReviewID == it.ReviewID {
+				continue
+			}
+			if d := Distance(e.Fingerprint, it.Fingerprint); d < bestDist {
+				best, bestDist = k, d
+			}
+		}
+		if best >= 0 {
+			m := known[best]
+			if m.GroupID == "" {
+				m.GroupID, m.CanonicalID = uuid.NewString(), m.ReviewID
+				if best < fromHistory {
+					started[best] = true
+				}
+			}
+			it.GroupID, it.CanonicalID = m.GroupID, m.CanonicalID
+		}
+		known = append(known, it)
+	}
+	for k := range started {
+		changed = append(changed, history[k])
+	}
+	return changed
+}
@@ -0,0 +1,104 @@
+package dedup
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	const (
+		a = Fingerprint(0)
+		b = Fingerprint(0b11)       // 2 bits from a
+		c = Fingerprint(0xFFFF0000) // far from both
+	)
+	t.Run("new group from batch", func(t *testing.T) {
+		items := []Entry{{ReviewID: "1", Fingerprint: a}, {ReviewID: "2", Fingerprint: b}, {ReviewID: "3", Fingerprint: c}}
+		changed := Assign(items, nil, 3)
+		if len(changed) != 0 {
+			t.Errorf("changed = %v, want none", changed)
+		}
+		if items[0].GroupID == "" || items[1].GroupID != items[0].GroupID {
+			t.Errorf("1 and 2 not grouped: %+v", items)
+		}
+		if items[0].CanonicalID != "1" || items[1].CanonicalID != "1" {
+			t.Errorf("canonical = %q, %q, want 1", items[0].CanonicalID, items[1].CanonicalID)
+		}
+		if items[0].Duplicate() || !items[1].Duplicate() {
+			t.Errorf("Duplicate() = %v, %v", items[0].Duplicate(), items[1].Duplicate())
+		}
+		if items[2].GroupID != "" {
+			t.Errorf("3 grouped: %+v", items[2])
+		}
+	})
+	t.Run("distance limit", func(t *testing.T) {
+		items := []Entry{{ReviewID: "1", Fingerprint: a}, {ReviewID: "2", Fingerprint: b}}
+		Assign(items, nil, 1)
+		if items[1].GroupID != "" {
+			t.Errorf("grouped beyond max distance: %+v", items[1])
+		}
+	})
+	t.Run("joins existing group", func(t *testing.T) {
+		history := []Entry{{ReviewID: "h", Fingerprint: a, GroupID: "g", CanonicalID: "h"}}
+		items := []Entry{{ReviewID: "1", Fingerprint: b}}
+		changed := Assign(items, history, 3)
+		if len(changed) != 0 {
+			t.Errorf("changed = %v, want none", changed)
+		}
+		if items[0].GroupID != "g" || items[0].CanonicalID != "h" {
+			t.Errorf("item = %+v, want group g canonical h", items[0])
+		}
+	})
+	t.Run("history starts a group", func(t *testing.T) {
+		history := []Entry{{ReviewID: "h", Fingerprint: a}}
+		items := []Entry{{ReviewID: "1", Fingerprint: b}}
+		changed := Assign(items, history, 3)
+		if len(changed) != 1 || changed[0].ReviewID != "h" || changed[0].CanonicalID != "h" {
+			t.Fatalf("changed = %+v, want h as canonical", changed)
+		}
+		if items[0].GroupID != changed[0].GroupID || items[0].CanonicalID != "h" {
+			t.Errorf("item = %+v, want group of h", items[0])
+		}
+	})
+	t.Run("nearest match wins", func(t *testing.T) {
+		history := []Entry{
+			{ReviewID: "far", Fingerprint: 0b111, GroupID: "g1", CanonicalID: "far"},
+			{ReviewID: "near", Fingerprint: 0b1, GroupID: "g2", CanonicalID: "near"},
+		}
+		items := []Entry{{ReviewID: "1", Fingerprint: a}}
+		Assign(items, history, 3)
+		if items[0].GroupID != "g2" {
+			t.Errorf("group = %q, want g2", items[0].GroupID)
+		}
+	})
+	t.Run("own history entry ignored", func(t *testing.T) {
+		history := []Entry{{ReviewID: "1", Fingerprint: a, GroupID: "g", CanonicalID: "x"}}
+		items := []Entry{{ReviewID: "1", Fingerprint: a, GroupID: "stale", CanonicalID: "stale"}}
+		Assign(items, history, 3)
+		if items[0].GroupID != "" || items[0].CanonicalID != "" {
+			t.Errorf("item = %+v, want ungrouped", items[0])
+		}
+	})
+	t.Run("one word edited", func(t *testing.T) {
+		fa, _ := Compute("The app keeps crashing every time I open the camera screen")
+		fb, _ := Compute("This app keeps crashing every time I open the camera screen")
+		d := Distance(fa, fb)
+		if d < Bands {
+			t.Fatalf("distance %d no longer needs probing beyond exact bands", d)
+		}
+		history := []Entry{{ReviewID: "h", Fingerprint: fa}}
+		items := []Entry{{ReviewID: "1", Fingerprint: fb}}
+		Assign(items, history, 6)
+		if items[0].CanonicalID != "h" {
+			t.Errorf("item = %+v, want grouped with h at distance %d", items[0], d)
+		}
+		found := false
+		for i, probes := range fb.Probes(6) {
+			if slices.Contains(probes, fa.Bands()[i]) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("probes of the edited review miss the original at distance %d", d)
+		}
+	})
+}
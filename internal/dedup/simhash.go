@@ -0,0 +1,128 @@
+// Package dedup fingerprints review texts with SimHash so near-identical
+// reviews can be grouped.
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	// shingleLen is the length in runes of the overlapping character
+	// n-grams a fingerprint is built from. Character shingles work the same
+	// for scripts with and without spaces between words.
+	shingleLen = 3
+	// minRunes is the shortest text worth fingerprinting; below it, unrelated
+	// short reviews ("great app!") would collide.
+	minRunes = 24
+)
+
+// Bands is the number of 16-bit slices a fingerprint is split into for
+// candidate lookup. Two fingerprints within Bands-1 bits of each other share
+// at least one band exactly; larger distances are covered by also looking up
+// band values a few bits away, see Probes.
+const Bands = 4
+
+// maxProbeBits is the most bits Probes flips within a band. Each extra bit
+// multiplies the probes, 1, 17 and 137 values per band for 0, 1 and 2 bits.
+const maxProbeBits = 2
+
+// MaxDistance is the largest distance Probes finds every candidate for.
+const MaxDistance = Bands*(maxProbeBits+1) - 1
+
+// flipMasks[r] holds every 16-bit mask with at most r bits set.
+var flipMasks = func() (out [maxProbeBits + 1][]int) {
+	for m := range 1 << 16 {
+		if n := bits.OnesCount16(uint16(m)); n <= maxProbeBits {
+			for r := n; r <= maxProbeBits; r++ {
+				out[r] = append(out[r], m)
+			}
+		}
+	}
+	return out
+}()
+
+// Fingerprint is a 64-bit SimHash.
+type Fingerprint uint64
+
+// Compute fingerprints text, lowercased with punctuation and whitespace
+// runs folded into single spaces. ok is false when text is too short to
+// fingerprint meaningfully.
+func Compute(text string) (fp Fingerprint, ok bool) {
+	rs := normalize(text)
+	if len(rs) < minRunes {
+		return 0, false
+	}
+	var weights [64]int
+	h := fnv.New64a()
+	for i := 0; i+shingleLen <= len(rs); i++ {
+		h.Reset()
+		h.Write([]byte(string(rs[i : i+shingleLen])))
+		sum := h.Sum64()
+		for b := range 64 {
+			if sum&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+	for b, w := range weights {
+		if w > 0 {
+			fp |= 1 << b
+		}
+	}
+	return fp, true
+}
+
+func normalize(text string) []rune {
+	out := make([]rune, 0, len(text))
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			out = append(out, r)
+			space = false
+			continue
+		}
+		if !space {
+			out = append(out, ' ')
+			space = true
+		}
+	}
+	if n := len(out); n > 0 && out[n-1] == ' ' {
+		out = out[:n-1]
+	}
+	return out
+}
+
+// Distance is the number of differing bits between two fingerprints.
+func Distance(a, b Fingerprint) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Bands splits the fingerprint into its 16-bit lookup bands.
+func (fp Fingerprint) Bands() [Bands]int {
+	var out [Bands]int
+	for i := range out {
+		out[i] = int(uint64(fp) >> (16 * i) & 0xFFFF)
+	}
+	return out
+}
+
+// Probes returns, per band, the band values within maxDistance/Bands bits of
+// fp's. Two fingerprints within maxDistance bits of each other differ in at
+// most that many bits in one of their bands, so looking up the probes finds
+// every candidate. maxDistance is capped at MaxDistance.
+func (fp Fingerprint) Probes(maxDistance int) [Bands][]int {
+	masks := flipMasks[min(max(maxDistance, 0)/Bands, maxProbeBits)]
+	var out [Bands][]int
+	for i, b := range fp.Bands() {
+		out[i] = make([]int, len(masks))
+		for k, m := range masks {
+			out[i][k] = b ^ m
+		}
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package dedup
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	const review = "The app keeps crashing every time I open the camera screen"
+	tests := []struct {
+		name    string
+		a, b    string
+		maxDist int
+		minDist int
+	}{
+		{"identical", review, review, 0, 0},
+		{"case and punctuation", review, "THE APP keeps crashing... every time I open the camera screen!!", 0, 0},
+		{"one word changed", review, "The app keeps crashing every time I open the camera screen now", 12, 0},
+		{"unrelated", review, "Lovely design and the subscription price is fair for what you get", 64, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa, ok := Compute(tt.a)
+			if !ok {
+				t.Fatalf("Compute(%q) not ok", tt.a)
+			}
+			fb, ok := Compute(tt.b)
+			if !ok {
+				t.Fatalf("Compute(%q) not ok", tt.b)
+			}
+			if d := Distance(fa, fb); d > tt.maxDist || d < tt.minDist {
+				t.Errorf("Distance = %d, want within [%d,%d]", d, tt.minDist, tt.maxDist)
+			}
+		})
+	}
+}
+
+func TestComputeTooShort(t *testing.T) {
+	for _, s := range []string{"", "great app!", "!!! ??? ... ,,, ;;; ::: --- ***"} {
+		if _, ok := Compute(s); ok {
+			t.Errorf("Compute(%q) ok, want too short", s)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b Fingerprint
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xFF, 0x0F, 4},
+		{0, ^Fingerprint(0), 64},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%#x, %#x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBands(t *testing.T) {
+	fp := Fingerprint(0x1111_2222_3333_4444)
+	if got, want := fp.Bands(), [Bands]int{0x4444, 0x3333, 0x2222, 0x1111}; got != want {
+		t.Errorf("Bands() = %#x, want %#x", got, want)
+	}
+}
+
+// Fingerprints within Bands-1 bits always share a band, which is what the
+// candidate lookup relies on.
+func TestBandsShareWithinDistance(t *testing.T) {
+	base := Fingerprint(0xDEAD_BEEF_CAFE_F00D)
+	flips := [][]int{{0}, {0, 16}, {3, 20, 40}, {15, 31, 47}, {63, 47, 31}}
+	for _, bits := range flips {
+		other := base
+		for _, b := range bits {
+			other ^= 1 << b
+		}
+		if Distance(base, other) != len(bits) {
+			t.Fatalf("flipping %v: distance %d", bits, Distance(base, other))
+		}
+		shared := false
+		for i, b := range base.Bands() {
+			if other.Bands()[i] == b {
+				shared = true
+			}
+		}
+		if !shared {
+			t.Errorf("flipping bits %v leaves no shared band", bits)
+		}
+	}
+}
+
+// Probes cover every fingerprint within the requested distance: one of its
+// bands is among the probes of the same band.
+func TestProbesWithinDistance(t *testing.T) {
+	base := Fingerprint(0xDEAD_BEEF_CAFE_F00D)
+	tests := []struct {
+		maxDistance int
+		bits        []int
+	}{
+		{3, []int{0, 16, 32}},
+		{6, []int{0, 1, 16, 17, 32, 33}},
+		{7, []int{0, 1, 16, 17, 32, 33, 48}},
+		{11, []int{0, 1, 2, 16, 17, 18, 32, 33, 34, 48, 49}},
+	}
+	for _, tt := range tests {
+		other := base
+		for _, b := range tt.bits {
+			other ^= 1 << b
+		}
+		found := false
+		for i, probes := range base.Probes(tt.maxDistance) {
+			if slices.Contains(probes, other.Bands()[i]) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Probes(%d) miss a fingerprint %d bits away", tt.maxDistance, len(tt.bits))
+		}
+	}
+}
+
+func TestProbesCount(t *testing.T) {
+	for _, tt := range []struct{ maxDistance, want int }{{0, 1}, {3, 1}, {4, 17}, {6, 17}, {8, 137}, {MaxDistance, 137}, {64, 137}} {
+		if got := len(Fingerprint(0).Probes(tt.maxDistance)[0]); got != tt.want {
+			t.Errorf("Probes(%d) has %d values per band, want %d", tt.maxDistance, got, tt.want)
+		}
+	}
+}
@@ -4,19 +4,35 @@ import (
 	wlg "github.com/abadojack/whatlanggo"
 )
 
-// DetectCode returns ISO-639-1 code and confidence in [0,1].
-// If language cannot be reliably detected, returns "und" and 0.
-func DetectCode(text string) (code string, conf float64) {
-	if len(text) == 0 {
-		return "und", 0
+// Undetermined is the code returned when no language can be identified.
+const Undetermined = "und"
+
+// Detector identifies the language of a text. Detect returns an ISO-639-1
+// code and a confidence within [0,1], or Undetermined and 0. The code is the
+// best guess even when confidence is low; callers decide what to accept.
+// Implementations must be safe for concurrent use.
+type Detector interface {
+	Detect(text string) (code string, conf float64)
+}
+
+// Coverage is implemented by detectors that only know some languages.
+type Coverage interface {
+	// Supports reports whether the detector can detect code at all.
+	Supports(code string) bool
+}
+
+// WhatlangDetector detects languages with whatlanggo, which covers about 80
+// languages across scripts.
+type WhatlangDetector struct{}
+
+func (WhatlangDetector) Detect(text string) (string, float64) {
+	if text == "" {
+		return Undetermined, 0
 	}
 	info := wlg.Detect(text)
-	if !info.IsReliable() {
-		return "und", 0
-	}
-	iso6391 := info.Lang.Iso6391()
-	if iso6391 == "" {
-		return "und", info.Confidence
+	code := info.Lang.Iso6391()
+	if code == "" {
+		return Undetermined, 0
 	}
-	return iso6391, info.Confidence
+	return code, info.Confidence
 }
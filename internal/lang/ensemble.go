@@ -0,0 +1,86 @@
+package lang
+
+import (
+	"math"
+	"sort"
+)
+
+// Weighted is an Ensemble member.
+type Weighted struct {
+	Detector Detector
+	Weight   float64
+}
+
+// Ensemble combines detectors by weighted vote: each member adds its weight
+// times its confidence to the language it detected. The winner's confidence
+// is its share of the weight of the members that had a say, so it is only
+// high when they agree and are confident. Members that found no language
+// have no say. Members that cannot detect the winning language (see
+// Coverage) only count with their weight times their confidence, so a
+// detector trained on fewer languages does not cap the confidence of the
+// others on the rest, but a confident vote of its own still does.
+type Ensemble struct {
+	members []Weighted
+}
+
+// NewEnsemble returns an ensemble of the members with a positive weight.
+func NewEnsemble(members ...Weighted) *Ensemble {
+	e := &Ensemble{}
+	for _, m := range members {
+		if m.Weight > 0 && m.Detector != nil {
+			e.members = append(e.members, m)
+		}
+	}
+	return e
+}
+
+func (e *Ensemble) Detect(text string) (string, float64) {
+	type result struct {
+		code string
+		conf float64
+	}
+	results := make([]result, len(e.members))
+	votes := map[string]float64{}
+	for i, m := range e.members {
+		code, conf := m.Detector.Detect(text)
+		results[i] = result{code, conf}
+		if code != Undetermined {
+			votes[code] += m.Weight * conf
+		}
+	}
+	if len(votes) == 0 {
+		return Undetermined, 0
+	}
+	codes := make([]string, 0, len(votes))
+	for code := range votes {
+		codes = append(codes, code)
+	}
+	// ties go to the alphabetically first code so results are deterministic
+	sort.Strings(codes)
+	best := codes[0]
+	for _, code := range codes[1:] {
+		if votes[code] > votes[best] {
+			best = code
+		}
+	}
+	var total float64
+	for i, m := range e.members {
+		r := results[i]
+		switch {
+		case r.code == Undetermined:
+		case !supports(m.Detector, best):
+			total += m.Weight * r.conf
+		default:
+			total += m.Weight
+		}
+	}
+	if total == 0 {
+		return Undetermined, 0
+	}
+	return best, math.Min(1, votes[best]/total)
+}
+
+func supports(d Detector, code string) bool {
+	c, ok := d.(Coverage)
+	return !ok || c.Supports(code)
+}
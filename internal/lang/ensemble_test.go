@@ -0,0 +1,89 @@
+package lang
+
+import (
+	"math"
+	"testing"
+)
+
+type stubDetector struct {
+	code string
+	conf float64
+}
+
+func (d stubDetector) Detect(string) (string, float64) { return d.code, d.conf }
+
+// coveredDetector implements Coverage for the supported languages only.
+type coveredDetector struct {
+	stubDetector
+	supported []string
+}
+
+func (d coveredDetector) Supports(code string) bool {
+	for _, c := range d.supported {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEnsemble(t *testing.T) {
+	tests := []struct {
+		name     string
+		members  []Weighted
+		wantCode string
+		wantConf float64
+	}{
+		{"agreement", []Weighted{
+			{stubDetector{code: "de", conf: 1}, 0.6},
+			{stubDetector{code: "de", conf: 0.5}, 0.4},
+		}, "de", 0.8},
+		{"disagreement", []Weighted{
+			{stubDetector{code: "de", conf: 1}, 0.6},
+			{stubDetector{code: "nl", conf: 1}, 0.4},
+		}, "de", 0.6},
+		{"abstaining member has no say", []Weighted{
+			{stubDetector{code: "de", conf: 0.9}, 0.6},
+			{stubDetector{code: Undetermined}, 0.4},
+		}, "de", 0.9},
+		{"member without the language only counts its confidence", []Weighted{
+			{stubDetector{code: "id", conf: 1}, 0.6},
+			{coveredDetector{stubDetector{code: "es", conf: 0.15}, []string{"en", "es"}}, 0.4},
+		}, "id", 0.6 / (0.6 + 0.4*0.15)},
+		{"confident member without the language still counts", []Weighted{
+			{stubDetector{code: "sv", conf: 0.67}, 0.6},
+			{coveredDetector{stubDetector{code: "tr", conf: 0.96}, []string{"tr"}}, 0.4},
+		}, "sv", 0.6 * 0.67 / (0.6 + 0.4*0.96)},
+		{"nothing found", []Weighted{
+			{stubDetector{code: Undetermined}, 1},
+		}, Undetermined, 0},
+		{"zero weight ignored", []Weighted{
+			{stubDetector{code: "fr", conf: 1}, 0},
+			{stubDetector{code: "it", conf: 0.5}, 1},
+		}, "it", 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, conf := NewEnsemble(tt.members...).Detect("text")
+			if code != tt.wantCode || math.Abs(conf-tt.wantConf) > 1e-9 {
+				t.Errorf("Detect() = %s %.3f, want %s %.3f", code, conf, tt.wantCode, tt.wantConf)
+			}
+		})
+	}
+}
+
+// Languages the n-gram profiles do not cover keep whatlang's confidence
+// above the default lang_detect_min_conf of 0.70.
+func TestEnsembleUnprofiledLanguages(t *testing.T) {
+	e := NewEnsemble(Weighted{WhatlangDetector{}, 0.6}, Weighted{NewNGramDetector(), 0.4})
+	tests := []struct{ code, text string }{
+		{"id", "Aplikasi ini sangat bagus dan membantu pekerjaan saya setiap hari, tapi sering keluar sendiri."},
+		{"vi", "Ứng dụng này rất tốt, tôi dùng mỗi ngày nhưng gần đây hay bị lỗi khi đăng nhập."},
+		{"ar", "التطبيق رائع جدا وأستخدمه كل يوم لكن التحديث الأخير سبب مشاكل في تسجيل الدخول"},
+	}
+	for _, tt := range tests {
+		if code, conf := e.Detect(tt.text); code != tt.code || conf < 0.7 {
+			t.Errorf("Detect(%q) = %s %.2f, want %s at 0.70 or more", tt.text, code, conf, tt.code)
+		}
+	}
+}
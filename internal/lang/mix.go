@@ -0,0 +1,85 @@
+package lang
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/quiby-ai/review-preprocessor/internal/textutil"
+)
+
+// Mix maps ISO-639-1 codes to their share of a text's letters; the shares
+// add up to 1.
+type Mix map[string]float64
+
+// Share returns the share of code, 0 when it does not occur.
+func (m Mix) Share(code string) float64 { return m[code] }
+
+// minSegmentRunes is the shortest clause detected on its own. Shorter
+// clauses stay with their sentence, as detection on a few words is noise.
+const minSegmentRunes = 24
+
+// DetectMix detects the language of every sentence of text, or of every
+// clause in sentences long enough to split, and weighs the results by letter
+// count. Segments detected with less than minConf confidence count as
+// fallback, usually the language detected for the whole text. It returns nil
+// for text without letters.
+func DetectMix(d Detector, text, fallback string, minConf float64) Mix {
+	counts := map[string]int{}
+	total := 0
+	for _, seg := range segments(text) {
+		n := letterCount(seg)
+		if n == 0 {
+			continue
+		}
+		code, conf := d.Detect(seg)
+		if code == Undetermined || conf < minConf {
+			code = fallback
+		}
+		counts[code] += n
+		total += n
+	}
+	if total == 0 {
+		return nil
+	}
+	mix := make(Mix, len(counts))
+	for code, n := range counts {
+		mix[code] = math.Round(float64(n)/float64(total)*1000) / 1000
+	}
+	return mix
+}
+
+// segments splits text into sentences and those into clauses at , ; and :
+// when every clause is at least minSegmentRunes long.
+func segments(text string) []string {
+	rs := []rune(text)
+	var out []string
+	for _, sp := range textutil.Sentences(text, "") {
+		sent := string(rs[sp.Start:sp.End])
+		clauses := strings.FieldsFunc(sent, func(r rune) bool { return r == ',' || r == ';' || r == ':' })
+		split := len(clauses) > 1
+		for _, c := range clauses {
+			if utf8.RuneCountInString(strings.TrimSpace(c)) < minSegmentRunes {
+				split = false
+				break
+			}
+		}
+		if split {
+			out = append(out, clauses...)
+		} else {
+			out = append(out, sent)
+		}
+	}
+	return out
+}
+
+func letterCount(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,92 @@
+package lang
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// fixedDetector answers by the first word of the text.
+type fixedDetector map[string]struct {
+	code string
+	conf float64
+}
+
+func (d fixedDetector) Detect(text string) (string, float64) {
+	r, ok := d[strings.Fields(text)[0]]
+	if !ok {
+		return Undetermined, 0
+	}
+	return r.code, r.conf
+}
+
+func TestDetectMix(t *testing.T) {
+	det := fixedDetector{
+		"Hola": {"es", 0.9},
+		"Pero": {"es", 0.4},
+		"Fix":  {"en", 0.55},
+		"Zzz":  {"xx", 0.2},
+	}
+	tests := []struct {
+		name    string
+		text    string
+		minConf float64
+		want    Mix
+	}{
+		{"no letters", "123 !!!", 0.5, nil},
+		{"single language", "Hola amigos.", 0.5, Mix{"es": 1}},
+		{"weighted by letters", "Hola amigos. Fix it.", 0.5, Mix{"es": 0.667, "en": 0.333}},
+		{"low confidence falls back", "Hola amigos. Fix it.", 0.7, Mix{"es": 1}},
+		{"undetermined falls back", "Hola amigos. Nada.", 0.5, Mix{"es": 1}},
+		{"below threshold falls back", "Hola amigos. Zzz zz.", 0.5, Mix{"es": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectMix(det, tt.text, "es", tt.minConf)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DetectMix() = %v, want %v", got, tt.want)
+			}
+			for code, share := range tt.want {
+				if math.Abs(got[code]-share) > 1e-9 {
+					t.Errorf("DetectMix() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// The Spanish review with an English sentence from the request: the English
+// sentence is only detected at about 0.54 on its own, so it counts at the
+// lang_mix_min_conf default but not at lang_detect_min_conf.
+func TestDetectMixSpanishWithEnglish(t *testing.T) {
+	const text = "La aplicación es muy útil para organizar mis tareas del trabajo y la uso todos los días. " +
+		"Me encanta el diseño y las notificaciones funcionan bien. " +
+		"But the new update broke the login screen completely."
+	mix := DetectMix(WhatlangDetector{}, text, "es", 0.5)
+	if en := mix.Share("en"); en < 0.2 || en > 0.4 {
+		t.Errorf("en share = %v in %v, want about 0.3", en, mix)
+	}
+	if es := mix.Share("es"); es < 0.6 || es > 0.8 {
+		t.Errorf("es share = %v in %v, want about 0.7", es, mix)
+	}
+	if mix := DetectMix(WhatlangDetector{}, text, "es", 0.7); mix.Share("en") != 0 {
+		t.Errorf("at 0.7 the English sentence counts: %v", mix)
+	}
+}
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"sentences", "One. Two. Three.", 3},
+		{"short clauses stay together", "Good app, works fine.", 1},
+		{"long clauses split", "La aplicación funciona de maravilla, but the latest update broke the login.", 2},
+	}
+	for _, tt := range tests {
+		if got := segments(tt.text); len(got) != tt.want {
+			t.Errorf("%s: segments(%q) = %q, want %d segments", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
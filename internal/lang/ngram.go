@@ -0,0 +1,123 @@
+package lang
+
+import (
+	"embed"
+	"io/fs"
+	"math"
+	"path"
+	"strings"
+	"unicode"
+)
+
+//go:embed profiles/*.txt
+var profileFS embed.FS
+
+const (
+	// maxGram is the longest character n-gram in a profile; all shorter ones
+	// are counted too so very short texts still have something to match.
+	maxGram = 3
+	// ngramSharpness scales the mean per-gram log-likelihood difference
+	// between languages into a confidence. Higher values make the detector
+	// surer of itself on less evidence.
+	ngramSharpness = 6
+	// ngramFullEvidence is the letter count from which a text's confidence is
+	// no longer scaled down; "ok" alone matches some profile well by chance.
+	ngramFullEvidence = 20
+)
+
+// profile holds the character n-gram counts of one language.
+type profile struct {
+	counts map[string]int
+	total  int
+}
+
+// NGramDetector is a character n-gram naive Bayes classifier trained on the
+// sample texts bundled in profiles/, one <code>.txt per language. It knows
+// fewer languages than WhatlangDetector but its profiles come from app
+// review style text.
+type NGramDetector struct {
+	profiles map[string]*profile
+	vocab    int
+}
+
+// NewNGramDetector trains a detector on the bundled profiles.
+func NewNGramDetector() *NGramDetector {
+	d := &NGramDetector{profiles: map[string]*profile{}}
+	paths, err := fs.Glob(profileFS, "profiles/*.txt")
+	if err != nil {
+		panic("lang: " + err.Error())
+	}
+	vocab := map[string]struct{}{}
+	for _, p := range paths {
+		b, err := profileFS.ReadFile(p)
+		if err != nil {
+			panic("lang: " + err.Error())
+		}
+		prof := &profile{counts: map[string]int{}}
+		for _, g := range ngrams(string(b)) {
+			prof.counts[g]++
+			prof.total++
+			vocab[g] = struct{}{}
+		}
+		d.profiles[strings.TrimSuffix(path.Base(p), ".txt")] = prof
+	}
+	d.vocab = len(vocab)
+	return d
+}
+
+// Supports reports whether a profile for code is bundled.
+func (d *NGramDetector) Supports(code string) bool {
+	_, ok := d.profiles[code]
+	return ok
+}
+
+// Detect scores text against every profile with add-one smoothing. The
+// confidence is the winner's softmax share over the mean per-gram
+// log-likelihoods, scaled down for texts shorter than ngramFullEvidence
+// letters.
+func (d *NGramDetector) Detect(text string) (string, float64) {
+	grams := ngrams(text)
+	if len(grams) == 0 || len(d.profiles) == 0 {
+		return Undetermined, 0
+	}
+	scores := make(map[string]float64, len(d.profiles))
+	best, bestScore := Undetermined, math.Inf(-1)
+	for code, prof := range d.profiles {
+		denom := math.Log(float64(prof.total + d.vocab))
+		var ll float64
+		for _, g := range grams {
+			ll += math.Log(float64(prof.counts[g]+1)) - denom
+		}
+		s := ll / float64(len(grams))
+		scores[code] = s
+		if s > bestScore || s == bestScore && code < best {
+			best, bestScore = code, s
+		}
+	}
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(ngramSharpness * (s - bestScore))
+	}
+	evidence := math.Min(1, float64(letterCount(text))/ngramFullEvidence)
+	return best, evidence / sum
+}
+
+// ngrams returns the 1- to maxGram-grams of the lowercased words of text,
+// each word padded with a space on both sides.
+func ngrams(text string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	}) {
+		rs := []rune(" " + w + " ")
+		for n := 1; n <= maxGram; n++ {
+			for i := 0; i+n <= len(rs); i++ {
+				if n == 1 && rs[i] == ' ' {
+					continue
+				}
+				out = append(out, string(rs[i:i+n]))
+			}
+		}
+	}
+	return out
+}
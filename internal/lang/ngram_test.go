@@ -0,0 +1,39 @@
+package lang
+
+import "testing"
+
+// The sentences are not part of the bundled profiles.
+func TestNGramDetector(t *testing.T) {
+	d := NewNGramDetector()
+	tests := []struct{ code, text string }{
+		{"en", "The camera filter option disappeared and exporting videos takes forever now."},
+		{"de", "Leider kann ich seit gestern keine Fotos mehr hochladen, bitte schnell beheben."},
+		{"es", "No puedo subir fotos desde ayer, por favor arréglenlo pronto."},
+		{"fr", "Impossible d'envoyer des photos depuis hier, merci de corriger vite."},
+		{"it", "Da ieri non riesco più a caricare foto, sistematelo presto per favore."},
+		{"pt", "Desde ontem não consigo enviar fotos, por favor corrijam logo."},
+		{"nl", "Sinds gisteren kan ik geen foto's meer uploaden, los het snel op."},
+		{"pl", "Od wczoraj nie mogę wysyłać zdjęć, proszę szybko to naprawić."},
+		{"tr", "Dünden beri fotoğraf yükleyemiyorum, lütfen hemen düzeltin."},
+		{"ru", "Со вчерашнего дня не могу загрузить фотографии, исправьте поскорее."},
+		{"uk", "Від учора не можу завантажити фотографії, виправте якнайшвидше."},
+		{"ja", "昨日から写真をアップロードできません。早く直してください。"},
+		{"zh", "从昨天开始就不能上传照片了，请尽快修复。"},
+		{"ko", "어제부터 사진을 올릴 수가 없어요. 빨리 고쳐 주세요."},
+	}
+	for _, tt := range tests {
+		code, conf := d.Detect(tt.text)
+		if code != tt.code {
+			t.Errorf("Detect(%q) = %s %.2f, want %s", tt.text, code, conf, tt.code)
+		}
+		if !d.Supports(tt.code) {
+			t.Errorf("Supports(%q) = false", tt.code)
+		}
+	}
+	if d.Supports("id") {
+		t.Error("Supports(id) = true without a profile")
+	}
+	if code, conf := d.Detect("123 !!!"); code != Undetermined || conf != 0 {
+		t.Errorf("Detect(no letters) = %s %.2f", code, conf)
+	}
+}
@@ -24,9 +24,26 @@ func (p *Producer) PublishEvent(ctx context.Context, key []byte, envelope events
 	return p.producer.PublishEvent(ctx, key, envelope)
 }
 
-func (p *Producer) BuildEnvelope(event events.PrepareCompleted, sagaID string) events.Envelope[any] {
+func (p *Producer) BuildEnvelope(event PrepareCompleted, sagaID string) events.Envelope[any] {
 	envelope := events.BuildEnvelope(event, events.PipelinePrepareCompleted, sagaID)
 	envelope.Meta.AppID = event.AppID
 
 	return envelope
 }
+
+func (p *Producer) BuildFailedEnvelope(event PrepareFailed, sagaID, appID string) events.Envelope[any] {
+	envelope := events.BuildEnvelope(event, events.PipelineFailed, sagaID)
+	envelope.Meta.AppID = appID
+
+	return envelope
+}
+
+// BuildDeadLetterEnvelope addresses the dead letter to topic; PublishEvent
+// routes by envelope type.
+func (p *Producer) BuildDeadLetterEnvelope(topic string, dl DeadLetter) events.Envelope[any] {
+	envelope := events.BuildEnvelope(dl, topic, dl.Envelope.SagaID)
+	envelope.Meta.AppID = dl.Envelope.Meta.AppID
+	envelope.Meta.Retries = dl.Envelope.Meta.Retries
+
+	return envelope
+}
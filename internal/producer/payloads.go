@@ -0,0 +1,61 @@
+package producer
+
+import (
+	"time"
+
+	"github.com/quiby-ai/common/pkg/events"
+	"github.com/quiby-ai/review-preprocessor/config"
+	"github.com/quiby-ai/review-preprocessor/internal/report"
+)
+
+// PrepareCompleted extends the shared completion payload with details about
+// how the preprocessor run went.
+type PrepareCompleted struct {
+	events.PrepareCompleted
+	ReusedCount      int `json:"reused_count"`
+	ReprocessedCount int `json:"reprocessed_count"`
+
+	// Options are the processing values in effect for this saga.
+	Options config.ProcessingOptions `json:"options"`
+	Report  *report.Report           `json:"report"`
+}
+
+// PrepareFailed extends the shared failure payload with the error message and
+// the number of attempts made before giving up.
+//
+// The shared contract marks Failed.Recoverable as required, which the
+// validator takes to mean it must be true, so a permanent failure with
+// Recoverable false would be rejected downstream. Until the contract accepts
+// false, Recoverable is always set and Retryable carries the actual
+// retryability.
+type PrepareFailed struct {
+	events.Failed
+	Retryable bool   `json:"retryable"`
+	Message   string `json:"message"`
+	Attempts  int    `json:"attempts"`
+}
+
+// NewPrepareFailed builds the failure payload for the prepare step.
+func NewPrepareFailed(code events.FailedCode, retryable bool, message string, attempts int) PrepareFailed {
+	return PrepareFailed{
+		Failed: events.Failed{
+			Step:        events.SagaStepPrepare,
+			Code:        code,
+			Recoverable: true,
+		},
+		Retryable: retryable,
+		Message:   message,
+		Attempts:  attempts,
+	}
+}
+
+// DeadLetter wraps a request that could not be processed together with the
+// error that stopped it.
+type DeadLetter struct {
+	Envelope  events.Envelope[events.PrepareRequest] `json:"envelope"`
+	Code      events.FailedCode                      `json:"code"`
+	Retryable bool                                   `json:"retryable"`
+	Error     string                                 `json:"error"`
+	Attempts  int                                    `json:"attempts"`
+	FailedAt  time.Time                              `json:"failed_at"`
+}
@@ -0,0 +1,19 @@
+package producer
+
+import (
+	"testing"
+
+	"github.com/quiby-ai/common/pkg/events"
+)
+
+func TestNewPrepareFailedValidates(t *testing.T) {
+	for _, retryable := range []bool{true, false} {
+		f := NewPrepareFailed(events.FailedCodeValidationError, retryable, "boom", 1)
+		if err := f.Validate(); err != nil {
+			t.Errorf("retryable=%v: Validate() = %v", retryable, err)
+		}
+		if f.Retryable != retryable {
+			t.Errorf("Retryable = %v, want %v", f.Retryable, retryable)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package profanity finds and masks profane words using per-language
+// lexicons.
+package profanity
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed lexicons/*.txt
+var builtin embed.FS
+
+// lexicon is the word list of one language. A trailing * in the source file
+// turns an entry into a prefix, so "fuck*" also matches "fucking".
+type lexicon struct {
+	words    map[string]struct{}
+	prefixes []string
+}
+
+func (l *lexicon) add(entry string) {
+	if p, ok := strings.CutSuffix(entry, "*"); ok {
+		l.prefixes = append(l.prefixes, p)
+		return
+	}
+	l.words[entry] = struct{}{}
+}
+
+func (l *lexicon) match(word string) bool {
+	if l == nil {
+		return false
+	}
+	if _, ok := l.words[word]; ok {
+		return true
+	}
+	for _, p := range l.prefixes {
+		if strings.HasPrefix(word, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter matches words against the lexicon of a text's language and the
+// English one, since English swearing turns up in reviews of every language.
+// A Filter is safe for concurrent use.
+type Filter struct {
+	lexicons map[string]*lexicon
+}
+
+// Load builds a Filter from the built-in lexicons plus, when dir is not
+// empty, every <lang>.txt in dir. Files in dir extend the built-in list of
+// the same language. Lines are lowercased; blank lines and lines starting
+// with # are ignored.
+func Load(dir string) (*Filter, error) {
+	f := &Filter{lexicons: map[string]*lexicon{}}
+	if err := f.loadFS(builtin, "lexicons"); err != nil {
+		return nil, fmt.Errorf("built-in lexicons: %w", err)
+	}
+	if dir != "" {
+		if err := f.loadFS(os.DirFS(dir), "."); err != nil {
+			return nil, fmt.Errorf("lexicons in %s: %w", dir, err)
+		}
+	}
+	return f, nil
+}
+
+func (f *Filter) loadFS(fsys fs.FS, dir string) error {
+	paths, err := fs.Glob(fsys, filepath.ToSlash(filepath.Join(dir, "*.txt")))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		file, err := fsys.Open(p)
+		if err != nil {
+			return err
+		}
+		lang := strings.ToLower(strings.TrimSuffix(filepath.Base(p), ".txt"))
+		err = f.read(lang, file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func (f *Filter) read(lang string, r io.Reader) error {
+	lex := f.lexicons[lang]
+	if lex == nil {
+		lex = &lexicon{words: map[string]struct{}{}}
+		f.lexicons[lang] = lex
+	}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.ToLower(strings.TrimSpace(sc.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lex.add(line)
+	}
+	return sc.Err()
+}
+
+// Find returns the byte spans of profane words in text. lang is the text's
+// ISO 639-1 code.
+func (f *Filter) Find(text, lang string) [][2]int {
+	if f == nil {
+		return nil
+	}
+	own, en := f.lexicons[lang], f.lexicons["en"]
+	var out [][2]int
+	start := -1
+	check := func(end int) {
+		if start < 0 {
+			return
+		}
+		w := strings.ToLower(text[start:end])
+		if own.match(w) || (lang != "en" && en.match(w)) {
+			out = append(out, [2]int{start, end})
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		check(i)
+	}
+	check(len(text))
+	return out
+}
+
+// Contains reports whether text has any profane word.
+func (f *Filter) Contains(text, lang string) bool {
+	return len(f.Find(text, lang)) > 0
+}
+
+// Mask replaces every profane word in text with its first letter followed by
+// asterisks, one per remaining character, and reports whether it masked any.
+func (f *Filter) Mask(text, lang string) (string, bool) {
+	spans := f.Find(text, lang)
+	if len(spans) == 0 {
+		return text, false
+	}
+	var b strings.Builder
+	b.Grow(len(text))
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(text[last:sp[0]])
+		word := text[sp[0]:sp[1]]
+		_, size := utf8.DecodeRuneInString(word)
+		b.WriteString(word[:size])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(word[size:])))
+		last = sp[1]
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
@@ -0,0 +1,82 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	f, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, text, lang, want string
+		masked                 bool
+	}{
+		{"clean", "Great app, works fine", "en", "Great app, works fine", false},
+		{"whole word", "this update is shit", "en", "this update is s***", true},
+		{"prefix entry", "What the FUCKING hell", "en", "What the F****** hell", true},
+		{"several words", "crap app, total bullshit!", "en", "c*** app, total b*******!", true},
+		{"inside longer words", "a classic passage about assets", "en", "a classic passage about assets", false},
+		{"prefix only at word start", "Scunthorpe cocktail bar", "en", "Scunthorpe cocktail bar", false},
+		{"exact entry not a prefix", "connexion trop lente", "fr", "connexion trop lente", false},
+		{"own language", "Die App ist scheiße", "de", "Die App ist s******", true},
+		{"english in other languages", "Die App ist shit", "de", "Die App ist s***", true},
+		{"other language not in english", "scheiße app", "en", "scheiße app", false},
+		{"language without lexicon", "putain, fuck this", "nl", "putain, f*** this", true},
+		{"cyrillic", "это просто говно", "ru", "это просто г****", true},
+		{"empty", "", "en", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, masked := f.Mask(tt.text, tt.lang)
+			if got != tt.want || masked != tt.masked {
+				t.Errorf("Mask(%q, %q) = %q, %v; want %q, %v", tt.text, tt.lang, got, masked, tt.want, tt.masked)
+			}
+			if c := f.Contains(tt.text, tt.lang); c != tt.masked {
+				t.Errorf("Contains(%q, %q) = %v, want %v", tt.text, tt.lang, c, tt.masked)
+			}
+		})
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"de.txt": "# extra German words\n\nMist\n",
+		"nl.txt": "kut*\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text, lang string
+		want       bool
+	}{
+		{"so ein Mist", "de", true},
+		{"Scheiße", "de", true}, // built-in list is kept
+		{"extra German words", "de", false},
+		{"kutapp", "nl", true},
+		{"kutapp", "en", false},
+	}
+	for _, tt := range tests {
+		if got := f.Contains(tt.text, tt.lang); got != tt.want {
+			t.Errorf("Contains(%q, %q) = %v, want %v", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestNilFilter(t *testing.T) {
+	var f *Filter
+	if got, masked := f.Mask("shit", "en"); got != "shit" || masked {
+		t.Errorf("nil Mask() = %q, %v", got, masked)
+	}
+}
@@ -0,0 +1,57 @@
+package report
+
+import "time"
+
+// Stage names used as keys of Report.TimingsMS.
+const (
+	StageFetch     = "fetch"
+	StageClean     = "clean"
+	StageTranslate = "translate"
+	StageDedup     = "dedup"
+	StageStore     = "store"
+	StageTotal     = "total"
+)
+
+// Report summarizes one preprocessing saga. Counts cover every review in the
+// range; translation counts only cover reviews reprocessed in this run.
+type Report struct {
+	Fetched     int `json:"fetched"`
+	Reused      int `json:"reused"`
+	Reprocessed int `json:"reprocessed"`
+	Contentful  int `json:"contentful"`
+	// Duplicates counts contentful reviews that are non-canonical members of
+	// a near-duplicate group.
+	Duplicates int `json:"duplicates"`
+
+	Skipped   map[string]int `json:"skipped"`
+	Languages map[string]int `json:"languages"`
+	// StageChanges counts, per cleaning stage, the texts it changed.
+	StageChanges map[string]int `json:"stage_changes"`
+
+	TranslationRequested int `json:"translation_requested"`
+	Translated           int `json:"translated"`
+	TranslationFailures  int `json:"translation_failures"`
+	FallbackUses         int `json:"fallback_uses"`
+
+	TimingsMS map[string]int64 `json:"timings_ms"`
+}
+
+func New() *Report {
+	return &Report{
+		Skipped:      map[string]int{},
+		Languages:    map[string]int{},
+		StageChanges: map[string]int{},
+		TimingsMS:    map[string]int64{},
+	}
+}
+
+func (r *Report) Skip(reason string) { r.Skipped[reason]++ }
+
+func (r *Report) Language(code string) { r.Languages[code]++ }
+
+func (r *Report) StageChanged(stage string) { r.StageChanges[stage]++ }
+
+// Time adds the time elapsed since start to the given stage.
+func (r *Report) Time(stage string, start time.Time) {
+	r.TimingsMS[stage] += time.Since(start).Milliseconds()
+}
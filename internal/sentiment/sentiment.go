@@ -0,0 +1,198 @@
+// Package sentiment scores English text with a VADER-style lexicon and rule
+// set. It runs offline and is meant for short texts such as app reviews.
+package sentiment
+
+import (
+	"bufio"
+	_ "embed"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:embed lexicon_en.txt
+var lexiconEN string
+
+// lexicon maps lowercase words to their valence within [-4,4].
+var lexicon = func() map[string]float64 {
+	m := map[string]float64{}
+	sc := bufio.NewScanner(strings.NewReader(lexiconEN))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word, val, ok := strings.Cut(line, " ")
+		if !ok {
+			panic("sentiment: malformed lexicon line: " + line)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			panic("sentiment: malformed lexicon line: " + line)
+		}
+		m[word] = v
+	}
+	return m
+}()
+
+// boosters intensify (positive) or dampen (negative) the word that follows
+// them, by VADER's increments.
+var boosters = map[string]float64{
+	"absolutely": 0.293, "completely": 0.293, "extremely": 0.293, "incredibly": 0.293,
+	"really": 0.293, "so": 0.293, "super": 0.293, "totally": 0.293, "very": 0.293,
+	"truly": 0.293, "highly": 0.293, "most": 0.293, "utterly": 0.293, "too": 0.293,
+	"barely": -0.293, "kinda": -0.293, "kind": -0.293, "slightly": -0.293,
+	"somewhat": -0.293, "sort": -0.293, "little": -0.293, "fairly": -0.293,
+}
+
+var negations = map[string]bool{
+	"not": true, "no": true, "never": true, "nothing": true, "none": true,
+	"nobody": true, "nowhere": true, "neither": true, "nor": true, "cannot": true,
+	"without": true, "hardly": true, "dont": true, "doesnt": true, "didnt": true,
+	"isnt": true, "wasnt": true, "arent": true, "werent": true, "cant": true,
+	"couldnt": true, "wont": true, "wouldnt": true, "shouldnt": true, "aint": true,
+	"havent": true, "hasnt": true,
+}
+
+const (
+	// negationScalar flips and softens a negated word, as in VADER.
+	negationScalar = -0.74
+	// capsIncrement is added to the magnitude of a word written in capitals
+	// amid otherwise mixed-case text.
+	capsIncrement = 0.733
+	// exclamationIncrement is added per exclamation mark, up to four.
+	exclamationIncrement = 0.292
+	// alpha normalizes the summed valence into [-1,1].
+	alpha = 15
+)
+
+type token struct {
+	word    string // lowercased, apostrophes removed
+	caps    bool
+	letters int
+	// clause numbers the punctuation-delimited clause the word is in;
+	// boosters and negations do not reach across clauses.
+	clause int
+}
+
+// Score rates the sentiment of English text within [-1,1], from most
+// negative to most positive; text without sentiment words scores 0.
+func Score(text string) float64 {
+	toks := tokenize(text)
+	mixedCase := !allCaps(toks)
+	vals := make([]float64, len(toks))
+	for i, t := range toks {
+		v, ok := lexicon[t.word]
+		if !ok {
+			continue
+		}
+		if t.caps && mixedCase {
+			v += math.Copysign(capsIncrement, v)
+		}
+		for dist := 1; dist <= 3 && i-dist >= 0 && toks[i-dist].clause == t.clause; dist++ {
+			prev := toks[i-dist]
+			scale := 1 - 0.05*float64(dist-1)
+			if b, ok := boosters[prev.word]; ok {
+				if prev.caps && mixedCase {
+					b += math.Copysign(capsIncrement, b)
+				}
+				// a booster moves v away from 0, a dampener towards it
+				if v < 0 {
+					b = -b
+				}
+				v += b * scale
+			}
+			if negations[prev.word] {
+				v *= negationScalar
+			}
+		}
+		vals[i] = v
+	}
+	// "but" shifts the weight onto the clause that follows it.
+	for i, t := range toks {
+		if t.word != "but" {
+			continue
+		}
+		for j := range vals {
+			switch {
+			case j < i:
+				vals[j] *= 0.5
+			case j > i:
+				vals[j] *= 1.5
+			}
+		}
+		break
+	}
+
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	if sum == 0 {
+		return 0
+	}
+	sum += math.Copysign(float64(min(strings.Count(text, "!"), 4))*exclamationIncrement, sum)
+	return sum / math.Sqrt(sum*sum+alpha)
+}
+
+// clauseBreaks end a clause.
+const clauseBreaks = ".,;:!?…\n"
+
+// tokenize splits text into words, dropping surrounding punctuation and
+// apostrophes within words so "don't" and "dont" look the same.
+func tokenize(text string) []token {
+	var out []token
+	clause := 0
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		f := strings.NewReplacer("'", "", "’", "").Replace(text[start:end])
+		start = -1
+		if f == "" {
+			return
+		}
+		t := token{word: strings.ToLower(f), clause: clause}
+		lower := false
+		for _, r := range f {
+			if unicode.IsLetter(r) {
+				t.letters++
+				lower = lower || unicode.IsLower(r)
+			}
+		}
+		t.caps = t.letters > 1 && !lower
+		out = append(out, t)
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '\'' || r == '’' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		if strings.ContainsRune(clauseBreaks, r) {
+			clause++
+		}
+	}
+	flush(len(text))
+	return out
+}
+
+// allCaps reports whether every word of two or more letters is in capitals,
+// in which case capitals carry no extra emphasis.
+func allCaps(toks []token) bool {
+	words := 0
+	for _, t := range toks {
+		if t.letters < 2 {
+			continue
+		}
+		if !t.caps {
+			return false
+		}
+		words++
+	}
+	return words > 0
+}
@@ -0,0 +1,107 @@
+package sentiment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreSign(t *testing.T) {
+	tests := []struct {
+		name, text string
+		want       int // -1, 0 or 1
+	}{
+		{"positive", "Great app, I love it", 1},
+		{"negative", "Terrible app, it crashes all the time", -1},
+		{"no sentiment words", "I installed it on my phone yesterday", 0},
+		{"empty", "", 0},
+		{"negated positive", "This app is not good", -1},
+		{"negated negative", "Honestly it is not bad", 1},
+		{"contraction negates", "I don't love it", -1},
+		{"negation within three words", "never really that useful", -1},
+		{"negation stops at clause end", "Not sure. Great app", 1},
+		{"but shifts to the second clause", "The design is great but it crashes and is useless", -1},
+		{"but with positive second clause", "It is slow sometimes but the app is amazing", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.text)
+			if sign(got) != tt.want {
+				t.Errorf("Score(%q) = %.3f, want sign %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreIntensity(t *testing.T) {
+	tests := []struct {
+		name, stronger, weaker string
+	}{
+		{"booster", "very good app", "good app"},
+		{"dampener", "good app", "slightly good app"},
+		{"booster on negative", "extremely bad app", "bad app"},
+		{"dampener on negative", "bad app", "slightly bad app"},
+		{"capitals amid lower case", "GOOD app", "good app"},
+		{"exclamations", "good app!!!", "good app"},
+		{"negation softens", "bad app", "not good app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, w := Score(tt.stronger), Score(tt.weaker)
+			if abs(s) <= abs(w) {
+				t.Errorf("|Score(%q)| = %.3f, want above |Score(%q)| = %.3f", tt.stronger, abs(s), tt.weaker, abs(w))
+			}
+		})
+	}
+}
+
+// The clause after "but" outweighs the one before it.
+func TestScoreBut(t *testing.T) {
+	up, down := Score("bad at first but good now"), Score("good at first but bad now")
+	if up <= 0 || down >= 0 {
+		t.Errorf("Score() = %.3f and %.3f, want positive then negative", up, down)
+	}
+}
+
+// Capitals carry no extra weight when the whole text is in capitals.
+func TestScoreAllCaps(t *testing.T) {
+	if a, b := Score("GOOD APP"), Score("good app"); a != b {
+		t.Errorf("Score(%q) = %.3f, want %.3f as for lower case", "GOOD APP", a, b)
+	}
+}
+
+func TestScoreRange(t *testing.T) {
+	texts := []string{
+		strings.Repeat("absolutely amazing, awesome, great, love it!!!! ", 50),
+		strings.Repeat("extremely terrible, useless, bad, hate it!!!! ", 50),
+		"good",
+		"bad",
+	}
+	for _, text := range texts {
+		if got := Score(text); got < -1 || got > 1 {
+			t.Errorf("Score(%.30q...) = %v, outside [-1,1]", text, got)
+		}
+	}
+	if got := Score(texts[0]); got < 0.99 {
+		t.Errorf("very positive text scores %.3f, want close to 1", got)
+	}
+	if got := Score(texts[1]); got > -0.99 {
+		t.Errorf("very negative text scores %.3f, want close to -1", got)
+	}
+}
+
+func sign(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
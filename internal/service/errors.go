@@ -0,0 +1,33 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/quiby-ai/common/pkg/events"
+)
+
+// Error carries the failure class of a Handle error so callers can report it
+// to the orchestrator and decide whether a retry makes sense.
+type Error struct {
+	Code      events.FailedCode
+	Retryable bool
+	Err       error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func classified(code events.FailedCode, retryable bool, err error) error {
+	return &Error{Code: code, Retryable: retryable, Err: err}
+}
+
+// Classify returns the failure code and retryability of err.
+// Errors that were not classified by the service are treated as UNKNOWN and retryable.
+func Classify(err error) (events.FailedCode, bool) {
+	var se *Error
+	if errors.As(err, &se) {
+		return se.Code, se.Retryable
+	}
+	return events.FailedCodeUnknown, true
+}
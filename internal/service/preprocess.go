@@ -2,32 +2,53 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/quiby-ai/common/pkg/events"
 	"github.com/quiby-ai/review-preprocessor/config"
+	"github.com/quiby-ai/review-preprocessor/internal/dedup"
 	"github.com/quiby-ai/review-preprocessor/internal/lang"
 	"github.com/quiby-ai/review-preprocessor/internal/producer"
+	"github.com/quiby-ai/review-preprocessor/internal/profanity"
+	"github.com/quiby-ai/review-preprocessor/internal/report"
+	"github.com/quiby-ai/review-preprocessor/internal/sentiment"
 	"github.com/quiby-ai/review-preprocessor/internal/storage"
 	"github.com/quiby-ai/review-preprocessor/internal/textutil"
 	"github.com/quiby-ai/review-preprocessor/internal/translate"
 )
 
+// ErrSagaInProgress is returned when another run of the same saga is still in flight.
+var ErrSagaInProgress = errors.New("saga already in progress")
+
 type PreprocessService struct {
 	raw   *storage.RawRepository
 	clean *storage.CleanRepository
+	sagas *storage.SagaRepository
+	opts  *storage.OptionsRepository
+	reps  *storage.ReportRepository
+	fps   *storage.FingerprintRepository
 	prod  *producer.Producer
 	cfg   config.ProcessingConfig
 	tr    translate.Translator
+	det   lang.Detector
+	prof  *profanity.Filter
 }
 
-func NewPreprocessService(raw *storage.RawRepository, clean *storage.CleanRepository, prod *producer.Producer, cfg config.ProcessingConfig, tr translate.Translator) *PreprocessService {
+func NewPreprocessService(raw *storage.RawRepository, clean *storage.CleanRepository, sagas *storage.SagaRepository, opts *storage.OptionsRepository, reps *storage.ReportRepository, fps *storage.FingerprintRepository, prod *producer.Producer, cfg config.ProcessingConfig, tr translate.Translator, det lang.Detector, prof *profanity.Filter) *PreprocessService {
 	if tr == nil {
 		tr = translate.Noop{}
 	}
-	return &PreprocessService{raw: raw, clean: clean, prod: prod, cfg: cfg, tr: tr}
+	if det == nil {
+		det = lang.WhatlangDetector{}
+	}
+	return &PreprocessService{raw: raw, clean: clean, sagas: sagas, opts: opts, reps: reps, fps: fps, prod: prod, cfg: cfg, tr: tr, det: det, prof: prof}
 }
 
 func parseTime(s string, def time.Time) time.Time {
@@ -43,71 +64,368 @@ func parseTime(s string, def time.Time) time.Time {
 	return def
 }
 
+// Handle runs the saga once. A redelivered request for a completed saga
+// republishes the stored completion event instead of rerunning it.
 func (s *PreprocessService) Handle(ctx context.Context, evt events.PrepareRequest, sagaID string) error {
+	claim, err := s.sagas.Begin(ctx, sagaID, evt.AppID, s.cfg.SagaStaleAfter)
+	if err != nil {
+		return classified(events.FailedCodeTempStorageUnavailable, true, fmt.Errorf("claim saga: %w", err))
+	}
+	if !claim.Acquired {
+		if claim.Status != storage.SagaCompleted {
+			return ErrSagaInProgress
+		}
+		var completed producer.PrepareCompleted
+		if err := json.Unmarshal(claim.CompletedEvent, &completed); err != nil {
+			return classified(events.FailedCodeSchemaMismatch, false, fmt.Errorf("decode stored completion: %w", err))
+		}
+		log.Printf("Saga %s already completed, republishing completion", sagaID)
+		return s.publishCompleted(ctx, completed, sagaID)
+	}
+
+	completed, err := s.run(ctx, evt)
+	if err != nil {
+		// the request context may already be cancelled; the ledger still has to learn about it
+		if ferr := s.sagas.Fail(context.WithoutCancel(ctx), sagaID, err); ferr != nil {
+			log.Printf("mark saga %s failed: %v", sagaID, ferr)
+		}
+		return err
+	}
+	if err := s.reps.Save(ctx, sagaID, evt.AppID, completed.Report); err != nil {
+		// the report is for observability only; losing it must not fail the saga
+		log.Printf("save report for saga %s: %v", sagaID, err)
+	}
+
+	// Record completion before publishing so a publish failure is retried by
+	// republishing the stored event rather than rerunning the saga.
+	payload, err := json.Marshal(completed)
+	if err != nil {
+		return fmt.Errorf("encode completion: %w", err)
+	}
+	counts := storage.SagaCounts{
+		CleanCount:       completed.CleanCount,
+		ReusedCount:      completed.ReusedCount,
+		ReprocessedCount: completed.ReprocessedCount,
+	}
+	if err := s.sagas.Complete(ctx, sagaID, counts, payload); err != nil {
+		return classified(events.FailedCodeWriteFailed, true, fmt.Errorf("complete saga: %w", err))
+	}
+	return s.publishCompleted(ctx, completed, sagaID)
+}
+
+func (s *PreprocessService) publishCompleted(ctx context.Context, completed producer.PrepareCompleted, sagaID string) error {
+	envelope := s.prod.BuildEnvelope(completed, sagaID)
+	if err := s.prod.PublishEvent(ctx, []byte(sagaID), envelope); err != nil {
+		return fmt.Errorf("publish prepare completed: %w", err)
+	}
+	return nil
+}
+
+// run cleans, translates and stores every review in the requested range,
+// using the global processing config with the app's overrides applied.
+func (s *PreprocessService) run(ctx context.Context, evt events.PrepareRequest) (producer.PrepareCompleted, error) {
+	opts, err := s.opts.Get(ctx, evt.AppID)
+	if errors.Is(err, storage.ErrInvalidOptions) {
+		return producer.PrepareCompleted{}, classified(events.FailedCodeValidationError, false, fmt.Errorf("load processing options: %w", err))
+	}
+	if err != nil {
+		return producer.PrepareCompleted{}, classified(events.FailedCodeTempStorageUnavailable, true, fmt.Errorf("load processing options: %w", err))
+	}
+	if err := opts.Validate(); err != nil {
+		return producer.PrepareCompleted{}, classified(events.FailedCodeValidationError, false, fmt.Errorf("processing options for app %s: %w", evt.AppID, err))
+	}
+	cfg := opts.Apply(s.cfg)
+	pipe, err := textutil.BuildPipeline(cfg.Pipeline)
+	if err != nil {
+		return producer.PrepareCompleted{}, classified(events.FailedCodeValidationError, false, fmt.Errorf("cleaning pipeline for app %s: %w", evt.AppID, err))
+	}
+
 	from := parseTime(evt.DateFrom, time.Time{})
 	to := parseTime(evt.DateTo, time.Now().UTC())
-
-	rawItems, err := s.raw.Fetch(ctx, storage.RawFilters{
+	filters := storage.RawFilters{
 		AppID:     evt.AppID,
 		Countries: evt.Countries,
 		DateFrom:  from,
 		DateTo:    to,
-	})
+	}
+
+	chunkSize := cfg.BatchSize
+	if chunkSize <= 0 {
+		chunkSize = 200
+	}
+
+	rep := report.New()
+	startedAt := time.Now()
+
+	// Walk the range page by page so memory stays bounded by the chunk size
+	// rather than by the number of reviews in the saga.
+	var cursor *storage.RawCursor
+	for {
+		fetchStart := time.Now()
+		rawItems, err := s.raw.FetchPage(ctx, filters, cursor, chunkSize)
+		rep.Time(report.StageFetch, fetchStart)
+		if err != nil {
+			return producer.PrepareCompleted{}, classified(events.FailedCodeSourceUnavailable, true, fmt.Errorf("fetch raw reviews: %w", err))
+		}
+		if len(rawItems) == 0 {
+			break
+		}
+
+		rep.Fetched += len(rawItems)
+		if err := s.processChunk(ctx, cfg, pipe, rawItems, rep); err != nil {
+			return producer.PrepareCompleted{}, err
+		}
+
+		if len(rawItems) < chunkSize {
+			break
+		}
+		last := rawItems[len(rawItems)-1]
+		cursor = &storage.RawCursor{ReviewedAt: last.ReviewedAt, ID: last.ID}
+	}
+
+	rep.Time(report.StageTotal, startedAt)
+
+	log.Printf("Processed %d reviews for app %s, %d contentful, %d reused, %d reprocessed, %d translated",
+		rep.Fetched, evt.AppID, rep.Contentful, rep.Reused, rep.Reprocessed, rep.Translated)
+
+	cleanCount := rep.Contentful
+	if cfg.DedupEnabled && cfg.DedupExcludeFromCount {
+		// Duplicates of a review outside the range are its only copy in there.
+		dups, err := s.clean.CountDuplicates(ctx, filters)
+		if err != nil {
+			return producer.PrepareCompleted{}, classified(events.FailedCodeTempStorageUnavailable, true, fmt.Errorf("count near-duplicates: %w", err))
+		}
+		cleanCount -= dups
+	}
+	if cfg.PublishIDsLimit > 0 && cleanCount > cfg.PublishIDsLimit {
+		cleanCount = cfg.PublishIDsLimit
+	}
+	return producer.PrepareCompleted{
+		PrepareCompleted: events.PrepareCompleted{
+			PrepareRequest: evt,
+			CleanCount:     cleanCount,
+		},
+		ReusedCount:      rep.Reused,
+		ReprocessedCount: rep.Reprocessed,
+		Options:          config.OptionsOf(cfg),
+		Report:           rep,
+	}, nil
+}
+
+// processChunk cleans, translates and persists one page of raw reviews.
+// Reviews whose stored input hash and pipeline version are unchanged are
+// reused as-is, keeping their existing translation.
+func (s *PreprocessService) processChunk(ctx context.Context, cfg config.ProcessingConfig, pipe *textutil.Pipeline, rawItems []storage.RawReview, rep *report.Report) error {
+	storeStart := time.Now()
+	ids := make([]string, len(rawItems))
+	for i, rr := range rawItems {
+		ids[i] = rr.ID
+	}
+	states, err := s.clean.FetchStates(ctx, ids)
+	rep.Time(report.StageStore, storeStart)
 	if err != nil {
-		return fmt.Errorf("fetch raw reviews: %w", err)
+		return classified(events.FailedCodeTempStorageUnavailable, true, fmt.Errorf("fetch clean states: %w", err))
 	}
 
-	log.Printf("Processing %d reviews for app %s", len(rawItems), evt.AppID)
+	pending := make([]storage.RawReview, 0, len(rawItems))
+	for _, rr := range rawItems {
+		prev, ok := states[rr.ID]
+		if ok && s.reusable(cfg, prev, rr.ContentHash()) {
+			rep.Reused++
+			if prev.IsContentful {
+				rep.Contentful++
+				if prev.Duplicate {
+					rep.Duplicates++
+				}
+				rep.Language(prev.Language)
+			} else if prev.SkipReason != "" {
+				rep.Skip(prev.SkipReason)
+			}
+			continue
+		}
+		pending = append(pending, rr)
+	}
+	rep.Reprocessed += len(pending)
+	if len(pending) == 0 {
+		return nil
+	}
 
-	cleanBatch, ids := s.buildCleanBatch(rawItems)
+	cleanStart := time.Now()
+	cleanBatch, contentfulIDs := s.buildCleanBatch(cfg, pipe, pending, rep)
+	rep.Time(report.StageClean, cleanStart)
+	rep.Contentful += len(contentfulIDs)
 
-	log.Printf("Cleaned %d reviews, %d contentful", len(cleanBatch), len(ids))
+	dedupStart := time.Now()
+	dups, err := s.groupDuplicates(ctx, cfg, pending, cleanBatch, rep)
+	rep.Time(report.StageDedup, dedupStart)
+	if err != nil {
+		return classified(events.FailedCodeTempStorageUnavailable, true, fmt.Errorf("find near-duplicates: %w", err))
+	}
 
-	s.runTranslations(ctx, &cleanBatch)
+	translateStart := time.Now()
+	err = s.runTranslations(ctx, cfg, &cleanBatch, rep)
+	rep.Time(report.StageTranslate, translateStart)
+	if err != nil {
+		return err
+	}
+	segmentTranslations(cleanBatch)
+	scoreSentiment(cfg, cleanBatch)
+	s.flagProfanity(cfg, cleanBatch)
 
-	if err := s.clean.UpsertBatch(ctx, cleanBatch); err != nil {
-		return fmt.Errorf("upsert clean reviews: %w", err)
+	storeStart = time.Now()
+	err = s.clean.UpsertBatch(ctx, cleanBatch, dups)
+	rep.Time(report.StageStore, storeStart)
+	if err != nil {
+		return classified(events.FailedCodeWriteFailed, true, fmt.Errorf("upsert clean reviews: %w", err))
 	}
+	return nil
+}
 
-	if s.cfg.PublishIDsLimit > 0 && len(ids) > s.cfg.PublishIDsLimit {
-		ids = ids[:s.cfg.PublishIDsLimit]
+// reusable reports whether a stored clean row can be kept as-is: its input
+// and pipeline version are unchanged and it is not missing a translation of
+// its content, title or developer response. A failed or timed-out translation
+// leaves the translated column NULL, so such rows are reprocessed until the
+// translation succeeds.
+func (s *PreprocessService) reusable(cfg config.ProcessingConfig, prev storage.CleanState, inputHash string) bool {
+	if prev.PipelineVersion != cfg.PipelineVersion || prev.InputHash != inputHash {
+		return false
+	}
+	if !prev.IsContentful || !s.translating(cfg) {
+		return true
 	}
-	prepareCompleted := events.PrepareCompleted{
-		PrepareRequest: evt,
-		CleanCount:     len(ids),
+	if needsTranslation(cfg, prev.Language, prev.LanguageMix) && (!prev.Translated || prev.HasTitle && !prev.TitleTranslated) {
+		return false
 	}
-	envelope := s.prod.BuildEnvelope(prepareCompleted, sagaID)
-	return s.prod.PublishEvent(ctx, []byte(sagaID), envelope)
+	return !cfg.TranslateResponses || prev.Response == nil || prev.ResponseTranslated ||
+		s.responseLanguage(cfg, *prev.Response, prev.Language) == "en"
+}
+
+// translating reports whether runTranslations can produce translations at
+// all; with the no-op translator a missing content_en is expected.
+func (s *PreprocessService) translating(cfg config.ProcessingConfig) bool {
+	if !cfg.TranslateEnabled || cfg.TranslateTargetLang != "en" {
+		return false
+	}
+	_, noop := s.tr.(translate.Noop)
+	return !noop
+}
+
+// groupDuplicates fingerprints the contentful reviews of the batch, matches
+// them against the app's stored fingerprints and each other, and records
+// the resulting groups on the batch. It returns what has to be stored along
+// with the batch, nil when dedup is disabled.
+func (s *PreprocessService) groupDuplicates(ctx context.Context, cfg config.ProcessingConfig, pending []storage.RawReview, batch []storage.CleanReview, rep *report.Report) (*storage.Duplicates, error) {
+	if !cfg.DedupEnabled {
+		return nil, nil
+	}
+	groups := &storage.Duplicates{AppID: pending[0].AppID, ReviewIDs: make([]string, len(pending))}
+	for i, rr := range pending {
+		groups.ReviewIDs[i] = rr.ID
+	}
+	var (
+		idx []int
+		fps []dedup.Fingerprint
+	)
+	for i, b := range batch {
+		if !b.IsContentful {
+			continue
+		}
+		fp, ok := dedup.Compute(b.ContentClean)
+		if !ok {
+			continue
+		}
+		groups.Entries = append(groups.Entries, dedup.Entry{ReviewID: b.ID, Fingerprint: fp})
+		idx = append(idx, i)
+		fps = append(fps, fp)
+	}
+	if len(groups.Entries) == 0 {
+		return groups, nil
+	}
+	history, err := s.fps.Candidates(ctx, groups.AppID, fps, cfg.DedupMaxDistance)
+	if err != nil {
+		return nil, err
+	}
+	// stored fingerprints of reviews being reprocessed are stale and replaced below
+	history = slices.DeleteFunc(history, func(e dedup.Entry) bool {
+		return slices.ContainsFunc(pending, func(rr storage.RawReview) bool { return rr.ID == e.ReviewID })
+	})
+	groups.Joined = dedup.Assign(groups.Entries, history, cfg.DedupMaxDistance)
+	for n, e := range groups.Entries {
+		if e.GroupID == "" {
+			continue
+		}
+		b := &batch[idx[n]]
+		b.DuplicateGroupID, b.CanonicalReviewID = &e.GroupID, &e.CanonicalID
+		if e.Duplicate() {
+			rep.Duplicates++
+		}
+	}
+	return groups, nil
 }
 
 // buildCleanBatch cleans, checks contentfulness, detects language, and builds the batch.
+// Title, content and developer response all go through the same cleaning pipeline.
 // It also determines which IDs to publish (contentful only) and which items require translation.
-func (s *PreprocessService) buildCleanBatch(rawItems []storage.RawReview) ([]storage.CleanReview, []string) {
+func (s *PreprocessService) buildCleanBatch(cfg config.ProcessingConfig, pipe *textutil.Pipeline, rawItems []storage.RawReview, rep *report.Report) ([]storage.CleanReview, []string) {
 	cleanBatch := make([]storage.CleanReview, 0, len(rawItems))
 	ids := make([]string, 0, len(rawItems))
 	for _, rr := range rawItems {
-		cleanText, ok := textutil.Clean(rr.Content, s.cfg.HTMLStrip, s.cfg.EmojiStrip, s.cfg.WhitespaceNormalize, s.cfg.MaxReviewLen, s.cfg.MinContentLen)
-		if !ok {
-			if s.cfg.SaveSkipped {
-				cleanBatch = append(cleanBatch, s.skippedClean(rr, cleanText))
+		title := pipe.Run(rr.Title, rr.Country)
+		content := pipe.Run(rr.Content, rr.Country)
+		for _, t := range content.Trace {
+			if t.Changed {
+				rep.StageChanged(t.Stage)
+			}
+		}
+		cleanText := content.Text
+		if utf8.RuneCountInString(cleanText) < cfg.MinContentLen {
+			reason := textutil.ShortReason(cleanText)
+			rep.Skip(string(reason))
+			if cfg.SaveSkipped {
+				cleanBatch = append(cleanBatch, s.skippedClean(cfg, rr, title, content, reason))
 			}
 			continue
 		}
-		if !textutil.IsContentful(cleanText, s.cfg.MinWords, s.cfg.MinChars, s.cfg.MinAlphaRatio) {
-			if s.cfg.SaveSkipped {
-				cleanBatch = append(cleanBatch, s.skippedClean(rr, cleanText))
+		if reason := textutil.CheckContentful(cleanText, cfg.MinWords, cfg.MinChars, cfg.MinAlphaRatio); reason != textutil.SkipNone {
+			rep.Skip(string(reason))
+			if cfg.SaveSkipped {
+				cleanBatch = append(cleanBatch, s.skippedClean(cfg, rr, title, content, reason))
 			}
 			continue
 		}
-		langCode, conf := lang.DetectCode(cleanText)
-		lowConf := langCode == "und" || conf < s.cfg.LangDetectMinConf
-		if lowConf && langCode == "und" {
-			langCode = s.cfg.DefaultLang
+		// Short content alone is a weak signal; let the title vote too.
+		detectText := cleanText
+		if title.Text != "" && utf8.RuneCountInString(cleanText) < cfg.LangDetectTitleBelow {
+			detectText = title.Text + "\n" + cleanText
+		}
+		langCode, conf := s.det.Detect(detectText)
+		lowConf := langCode == lang.Undetermined || conf < cfg.LangDetectMinConf
+		// Only confidently detected English is checked against the English word list.
+		spamLang := langCode
+		if lowConf {
+			spamLang = ""
 		}
+		spam := textutil.SpamScore(cleanText, spamLang)
+		if cfg.SpamThreshold > 0 && spam > cfg.SpamThreshold {
+			rep.Skip(string(textutil.SkipSpam))
+			if cfg.SaveSkipped {
+				row := s.skippedClean(cfg, rr, title, content, textutil.SkipSpam)
+				row.SpamScore = &spam
+				cleanBatch = append(cleanBatch, row)
+			}
+			continue
+		}
+		if lowConf {
+			langCode = cfg.DefaultLang
+		}
+		rep.Language(langCode)
+		docs := []textutil.Doc{title, content}
 		var respTextClean *string
 		if rr.ResponseContent.Valid {
-			if v, ok := textutil.Clean(rr.ResponseContent.String, s.cfg.HTMLStrip, s.cfg.EmojiStrip, s.cfg.WhitespaceNormalize, s.cfg.MaxReviewLen, s.cfg.MinContentLen); ok {
-				respTextClean = &v
+			resp := pipe.Run(rr.ResponseContent.String, rr.Country)
+			if utf8.RuneCountInString(resp.Text) >= cfg.MinContentLen {
+				respTextClean = &resp.Text
+				docs = append(docs, resp)
 			}
 		}
 		var respDate *time.Time
@@ -115,93 +433,296 @@ func (s *PreprocessService) buildCleanBatch(rawItems []storage.RawReview) ([]sto
 			d := rr.ResponseDate.Time
 			respDate = &d
 		}
-		cleanBatch = append(cleanBatch, storage.CleanReview{
+		row := storage.CleanReview{
 			ID:                   rr.ID,
 			AppID:                rr.AppID,
 			Country:              rr.Country,
 			Rating:               rr.Rating,
-			Title:                rr.Title,
+			Title:                title.Text,
 			ContentClean:         cleanText,
+			SentencesClean:       textutil.Sentences(cleanText, langCode),
+			IsTruncated:          content.Truncated,
+			OriginalLength:       content.OriginalLen,
+			Emojis:               emojis(docs...),
+			PIITypes:             piiTypes(docs...),
+			SpamScore:            &spam,
 			Language:             langCode,
+			LanguageMix:          lang.DetectMix(s.det, cleanText, langCode, cfg.LangMixMinConf),
 			IsContentful:         true,
 			ReviewedAt:           rr.ReviewedAt,
 			ResponseDate:         respDate,
 			ResponseContentClean: respTextClean,
-		})
+			InputHash:            rr.ContentHash(),
+			PipelineVersion:      cfg.PipelineVersion,
+		}
+		if em := content.Emphasis; em != nil {
+			row.ElongationCount, row.IsShouting = &em.Elongations, &em.Shouting
+		}
+		cleanBatch = append(cleanBatch, row)
 		ids = append(ids, rr.ID)
 	}
 	return cleanBatch, ids
 }
 
-func (s *PreprocessService) skippedClean(rr storage.RawReview, cleanText string) storage.CleanReview {
+func (s *PreprocessService) skippedClean(cfg config.ProcessingConfig, rr storage.RawReview, title, content textutil.Doc, reason textutil.SkipReason) storage.CleanReview {
 	return storage.CleanReview{
-		ID:           rr.ID,
-		AppID:        rr.AppID,
-		Country:      rr.Country,
-		Rating:       rr.Rating,
-		Title:        rr.Title,
-		ContentClean: cleanText,
-		Language:     s.cfg.DefaultLang,
-		IsContentful: false,
-		ReviewedAt:   rr.ReviewedAt,
+		ID:              rr.ID,
+		AppID:           rr.AppID,
+		Country:         rr.Country,
+		Rating:          rr.Rating,
+		Title:           title.Text,
+		ContentClean:    content.Text,
+		IsTruncated:     content.Truncated,
+		OriginalLength:  content.OriginalLen,
+		Emojis:          emojis(title, content),
+		PIITypes:        piiTypes(title, content),
+		Language:        cfg.DefaultLang,
+		IsContentful:    false,
+		SkipReason:      string(reason),
+		ReviewedAt:      rr.ReviewedAt,
+		InputHash:       rr.ContentHash(),
+		PipelineVersion: cfg.PipelineVersion,
+	}
+}
+
+// piiTypes lists the distinct PII types redacted from the fields of one review.
+func piiTypes(docs ...textutil.Doc) []string {
+	var out []string
+	for _, d := range docs {
+		for _, t := range d.PII {
+			if !slices.Contains(out, string(t)) {
+				out = append(out, string(t))
+			}
+		}
+	}
+	return out
+}
+
+// emojis collects the emoji extracted from the fields of one review, in field
+// order.
+func emojis(docs ...textutil.Doc) []string {
+	var out []string
+	for _, d := range docs {
+		out = append(out, d.Emojis...)
+	}
+	return out
+}
+
+// segmentTranslations splits the English translations into sentences so
+// consumers of content_en share the segmentation of content_clean.
+func segmentTranslations(batch []storage.CleanReview) {
+	for i := range batch {
+		if b := &batch[i]; b.ContentEN != nil {
+			b.SentencesEN = textutil.Sentences(*b.ContentEN, "en")
+		}
+	}
+}
+
+// scoreSentiment scores contentful reviews that have English text, the
+// translation or the content itself, and flags ratings the text contradicts.
+// The title is scored along with the content since it often carries the
+// verdict.
+func scoreSentiment(cfg config.ProcessingConfig, batch []storage.CleanReview) {
+	if !cfg.SentimentEnabled {
+		return
+	}
+	t := cfg.SentimentMismatchThreshold
+	for i := range batch {
+		b := &batch[i]
+		if !b.IsContentful {
+			continue
+		}
+		var title, text string
+		switch {
+		case b.ContentEN != nil:
+			text = *b.ContentEN
+			if b.TitleEN != nil {
+				title = *b.TitleEN
+			}
+		case !needsTranslation(cfg, b.Language, b.LanguageMix):
+			title, text = b.Title, b.ContentClean
+		default:
+			continue
+		}
+		if title != "" {
+			text = title + ".\n" + text
+		}
+		score := sentiment.Score(text)
+		b.SentimentScore = &score
+		b.RatingMismatch = t > 0 && (b.Rating >= 4 && score <= -t || b.Rating <= 2 && score >= t)
 	}
 }
 
-// runTranslations translates only items that are non-EN or had low-confidence detection.
-func (s *PreprocessService) runTranslations(ctx context.Context, batch *[]storage.CleanReview) {
-	if !s.cfg.TranslateEnabled || s.cfg.TranslateTargetLang != "en" {
+// flagProfanity marks contentful reviews whose content or English translation
+// contains profanity and, when masking is on, fills the display-safe copies.
+// It runs after translation so both texts are available.
+func (s *PreprocessService) flagProfanity(cfg config.ProcessingConfig, batch []storage.CleanReview) {
+	if s.prof == nil {
 		return
 	}
+	for i := range batch {
+		b := &batch[i]
+		if !b.IsContentful {
+			continue
+		}
+		if masked, ok := s.prof.Mask(b.ContentClean, b.Language); ok {
+			b.HasProfanity = true
+			if cfg.ProfanityMask {
+				b.ContentDisplay = &masked
+			}
+		}
+		if b.ContentEN != nil {
+			if masked, ok := s.prof.Mask(*b.ContentEN, "en"); ok {
+				b.HasProfanity = true
+				if cfg.ProfanityMask {
+					b.ContentENDisplay = &masked
+				}
+			}
+		}
+	}
+}
+
+const (
+	titleItemSuffix    = ":title"
+	responseItemSuffix = ":response"
+)
+
+// responseLanguage detects the developer response language. Developers usually
+// answer in the reviewer's language, so that is the fallback.
+func (s *PreprocessService) responseLanguage(cfg config.ProcessingConfig, response, reviewLanguage string) string {
+	if code, conf := s.det.Detect(response); code != lang.Undetermined && conf >= cfg.LangDetectMinConf {
+		return code
+	}
+	return reviewLanguage
+}
+
+// needsTranslation reports whether a review's content has too much
+// non-English text to be used as English as-is.
+func needsTranslation(cfg config.ProcessingConfig, language string, mix lang.Mix) bool {
+	if mix == nil || cfg.TranslateMixThreshold <= 0 {
+		return language != "en"
+	}
+	return 1-mix.Share("en") >= cfg.TranslateMixThreshold
+}
+
+// runTranslations translates content with too much non-English text; see needsTranslation.
+// Sub-batches run concurrently; it returns an error only if ctx was cancelled.
+func (s *PreprocessService) runTranslations(ctx context.Context, cfg config.ProcessingConfig, batch *[]storage.CleanReview, rep *report.Report) error {
+	if !cfg.TranslateEnabled || cfg.TranslateTargetLang != "en" {
+		return nil
+	}
+	// Titles and developer responses travel in the same requests as content under
+	// suffixed item IDs; dst maps each item ID to the field its translation is written to.
 	toTranslate := make([]translate.Item, 0)
-	idToIndex := make(map[string]int)
+	dst := make(map[string]**string)
 	for i := range *batch {
 		b := &(*batch)[i]
 		// Skip non-contentful
 		if !b.IsContentful {
 			continue
 		}
-		// Only translate non-English content or low-confidence detections
-		if b.Language != "en" {
+		if needsTranslation(cfg, b.Language, b.LanguageMix) {
 			toTranslate = append(toTranslate, translate.Item{ID: b.ID, Text: b.ContentClean})
-			idToIndex[b.ID] = i
+			dst[b.ID] = &b.ContentEN
+			if b.Title != "" {
+				id := b.ID + titleItemSuffix
+				toTranslate = append(toTranslate, translate.Item{ID: id, Text: b.Title})
+				dst[id] = &b.TitleEN
+			}
+		}
+		if cfg.TranslateResponses && b.ResponseContentClean != nil && s.responseLanguage(cfg, *b.ResponseContentClean, b.Language) != "en" {
+			id := b.ID + responseItemSuffix
+			toTranslate = append(toTranslate, translate.Item{ID: id, Text: *b.ResponseContentClean})
+			dst[id] = &b.ResponseContentEN
 		}
 	}
 	if len(toTranslate) == 0 {
-		return
+		return nil
 	}
-	bs := s.cfg.TranslateBatchSize
+	rep.TranslationRequested += len(toTranslate)
+	bs := cfg.TranslateBatchSize
 	if bs <= 0 {
 		bs = 20
 	}
+	subs := make([][]translate.Item, 0, (len(toTranslate)+bs-1)/bs)
 	for i := 0; i < len(toTranslate); i += bs {
-		j := i + bs
-		if j > len(toTranslate) {
-			j = len(toTranslate)
-		}
-		sub := toTranslate[i:j]
-		tctx := ctx
-		if s.cfg.TranslateTimeout > 0 {
-			var cancel context.CancelFunc
-			tctx, cancel = context.WithTimeout(ctx, s.cfg.TranslateTimeout)
-			defer cancel()
-		}
-		res, err := s.tr.TranslateBatch(tctx, sub, s.cfg.TranslateTargetLang)
-		if err != nil {
-			log.Printf("translation batch failed: %v", err)
+		subs = append(subs, toTranslate[i:min(i+bs, len(toTranslate))])
+	}
+
+	results := s.translateBatches(ctx, cfg, subs)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// merge in sub-batch order so the outcome does not depend on worker scheduling
+	for n, sub := range subs {
+		res := results[n]
+		if res == nil {
+			rep.TranslationFailures += len(sub)
 			continue
 		}
 		for _, it := range sub {
 			r, ok := res[it.ID]
 			if !ok {
+				rep.TranslationFailures++
 				continue
 			}
-			idx := idToIndex[it.ID]
-			// Only update ContentEN with translated text, don't overwrite original language
+			if r.Fallback {
+				rep.FallbackUses++
+			}
+			// Only set the *EN field with translated text, don't overwrite original language
 			if r.Translated != "" {
 				en := r.Translated
-				(*batch)[idx].ContentEN = &en
+				*dst[it.ID] = &en
+				rep.Translated++
 			}
 			// Don't overwrite the original language - keep what was detected in buildCleanBatch
 		}
 	}
+	return nil
+}
+
+// translateBatches sends sub-batches to the translator on a pool of up to
+// TranslateConcurrency workers. results[i] belongs to subs[i] and is nil when
+// that sub-batch failed or was not started before ctx was cancelled.
+func (s *PreprocessService) translateBatches(ctx context.Context, cfg config.ProcessingConfig, subs [][]translate.Item) []map[string]translate.Result {
+	results := make([]map[string]translate.Result, len(subs))
+	workers := min(max(cfg.TranslateConcurrency, 1), len(subs))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				results[n] = s.translateBatch(ctx, cfg, subs[n])
+			}
+		}()
+	}
+feed:
+	for n := range subs {
+		select {
+		case jobs <- n:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func (s *PreprocessService) translateBatch(ctx context.Context, cfg config.ProcessingConfig, items []translate.Item) map[string]translate.Result {
+	if cfg.TranslateTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.TranslateTimeout)
+		defer cancel()
+	}
+	res, err := s.tr.TranslateBatch(ctx, items, cfg.TranslateTargetLang)
+	if err != nil {
+		log.Printf("translation batch failed: %v", err)
+		return nil
+	}
+	return res
 }
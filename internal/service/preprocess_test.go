@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quiby-ai/review-preprocessor/config"
+	"github.com/quiby-ai/review-preprocessor/internal/lang"
+	"github.com/quiby-ai/review-preprocessor/internal/storage"
+	"github.com/quiby-ai/review-preprocessor/internal/translate"
+)
+
+type stubTranslator struct{}
+
+func (stubTranslator) TranslateBatch(ctx context.Context, items []translate.Item, target string) (map[string]translate.Result, error) {
+	return nil, nil
+}
+
+func TestReusable(t *testing.T) {
+	cfg := config.ProcessingConfig{
+		PipelineVersion:       "7",
+		LangDetectMinConf:     0.5,
+		TranslateResponses:    true,
+		TranslateEnabled:      true,
+		TranslateTargetLang:   "en",
+		TranslateMixThreshold: 0.2,
+	}
+	const hash = "h1"
+	germanResponse := "Vielen Dank für Ihre Bewertung, wir kümmern uns so schnell wie möglich darum."
+	englishResponse := "Thank you for your review, we are looking into this issue right now."
+	stored := func(mut func(*storage.CleanState)) storage.CleanState {
+		st := storage.CleanState{InputHash: hash, PipelineVersion: "7", IsContentful: true, Language: "de", Translated: true}
+		mut(&st)
+		return st
+	}
+	tests := []struct {
+		name string
+		tr   translate.Translator
+		cfg  func(*config.ProcessingConfig)
+		prev storage.CleanState
+		want bool
+	}{
+		{"unchanged and translated", stubTranslator{}, nil, stored(func(*storage.CleanState) {}), true},
+		{"input changed", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.InputHash = "h0" }), false},
+		{"pipeline version changed", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.PipelineVersion = "6" }), false},
+		{"failed translation is retried", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.Translated = false }), false},
+		{"english needs no translation", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.Translated, s.Language = false, "en" }), true},
+		{"mostly english mix needs no translation", stubTranslator{}, nil, stored(func(s *storage.CleanState) {
+			s.Translated, s.LanguageMix = false, lang.Mix{"en": 0.9, "de": 0.1}
+		}), true},
+		{"mixed review missing translation is retried", stubTranslator{}, nil, stored(func(s *storage.CleanState) {
+			s.Translated, s.Language, s.LanguageMix = false, "en", lang.Mix{"en": 0.6, "es": 0.4}
+		}), false},
+		{"failed title translation is retried", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.HasTitle = true }), false},
+		{"translated title", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.HasTitle, s.TitleTranslated = true, true }), true},
+		{"english review title needs no translation", stubTranslator{}, nil, stored(func(s *storage.CleanState) {
+			s.Translated, s.Language, s.HasTitle = false, "en", true
+		}), true},
+		{"failed response translation is retried", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.Response = &germanResponse }), false},
+		{"translated response", stubTranslator{}, nil, stored(func(s *storage.CleanState) {
+			s.Response, s.ResponseTranslated = &germanResponse, true
+		}), true},
+		{"response translation turned off", stubTranslator{}, func(c *config.ProcessingConfig) { c.TranslateResponses = false }, stored(func(s *storage.CleanState) {
+			s.Response = &germanResponse
+		}), true},
+		{"english response needs no translation", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.Response = &englishResponse }), true},
+		{"skipped rows are not translated", stubTranslator{}, nil, stored(func(s *storage.CleanState) { s.Translated, s.IsContentful = false, false }), true},
+		{"translation disabled", stubTranslator{}, func(c *config.ProcessingConfig) { c.TranslateEnabled = false }, stored(func(s *storage.CleanState) { s.Translated = false }), true},
+		{"no-op translator", translate.Noop{}, nil, stored(func(s *storage.CleanState) { s.Translated = false }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			if tt.cfg != nil {
+				tt.cfg(&c)
+			}
+			s := &PreprocessService{tr: tt.tr, det: lang.WhatlangDetector{}}
+			if got := s.reusable(c, tt.prev, hash); got != tt.want {
+				t.Errorf("reusable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,14 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"github.com/lib/pq"
+	"github.com/quiby-ai/review-preprocessor/internal/dedup"
+	"github.com/quiby-ai/review-preprocessor/internal/lang"
+	"github.com/quiby-ai/review-preprocessor/internal/textutil"
 )
 
 type CleanRepository struct{ db *sql.DB }
@@ -16,26 +23,129 @@ type CleanReview struct {
 	Country              string
 	Rating               int16
 	Title                string
+	TitleEN              *string
 	ContentClean         string
+	SentencesClean       []textutil.Span
+	IsTruncated          bool
+	OriginalLength       int
+	Emojis               []string
+	PIITypes             []string
+	ElongationCount      *int
+	IsShouting           *bool
+	SpamScore            *float64
 	Language             string
+	LanguageMix          lang.Mix
 	ContentEN            *string
+	SentencesEN          []textutil.Span
+	SentimentScore       *float64
+	RatingMismatch       bool
+	HasProfanity         bool
+	ContentDisplay       *string
+	ContentENDisplay     *string
 	IsContentful         bool
+	SkipReason           string
+	DuplicateGroupID     *string
+	CanonicalReviewID    *string
 	ReviewedAt           time.Time
 	ResponseDate         *time.Time
 	ResponseContentClean *string
+	ResponseContentEN    *string
+	InputHash            string
+	PipelineVersion      string
+}
+
+// CleanState is the subset of a stored clean review needed to decide whether
+// it can be reused as-is.
+type CleanState struct {
+	InputHash       string
+	PipelineVersion string
+	IsContentful    bool
+	Language        string
+	LanguageMix     lang.Mix
+	// Translated is set when content_en is stored.
+	Translated bool
+	// HasTitle is set when the review has a title; TitleTranslated when
+	// title_en is stored.
+	HasTitle        bool
+	TitleTranslated bool
+	// Response is the cleaned developer response, nil when there is none;
+	// ResponseTranslated is set when response_content_en is stored.
+	Response           *string
+	ResponseTranslated bool
+	// Duplicate is set for non-canonical members of a near-duplicate group.
+	Duplicate bool
+	// SkipReason is why a non-contentful row was skipped, empty otherwise.
+	SkipReason string
 }
 
-func (r *CleanRepository) UpsertBatch(ctx context.Context, items []CleanReview) error {
-	if len(items) == 0 {
+// FetchStates returns the stored state for the given review IDs, keyed by ID.
+// IDs without a clean row are absent from the result.
+func (r *CleanRepository) FetchStates(ctx context.Context, ids []string) (map[string]CleanState, error) {
+	out := make(map[string]CleanState, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(input_hash, ''), COALESCE(pipeline_version, ''), is_contentful, COALESCE(language, ''),
+			language_mix, content_en IS NOT NULL, title <> '', title_en IS NOT NULL,
+			response_content_clean, response_content_en IS NOT NULL,
+			COALESCE(canonical_review_id <> id, FALSE), COALESCE(skip_reason, '')
+		FROM clean_reviews
+		WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var st CleanState
+		var mix []byte
+		if err := rows.Scan(&id, &st.InputHash, &st.PipelineVersion, &st.IsContentful, &st.Language, &mix, &st.Translated,
+			&st.HasTitle, &st.TitleTranslated, &st.Response, &st.ResponseTranslated, &st.Duplicate, &st.SkipReason); err != nil {
+			return nil, err
+		}
+		if mix != nil {
+			if err := json.Unmarshal(mix, &st.LanguageMix); err != nil {
+				return nil, fmt.Errorf("language_mix of %s: %w", id, err)
+			}
+		}
+		out[id] = st
+	}
+	return out, rows.Err()
+}
+
+// UpsertBatch stores items and, when dups is set, the batch's near-duplicate
+// fingerprints and groups in one transaction, so a stored row is never left
+// without the fingerprint a later run would reuse it with.
+func (r *CleanRepository) UpsertBatch(ctx context.Context, items []CleanReview, dups *Duplicates) error {
+	if len(items) == 0 && dups == nil {
 		return nil
 	}
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	if err := upsertClean(ctx, tx, items); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if dups != nil {
+		if err := saveFingerprints(ctx, tx, dups); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := setDuplicateGroups(ctx, tx, dups.Joined); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func upsertClean(ctx context.Context, tx *sql.Tx, items []CleanReview) error {
 	stmt, err := tx.PrepareContext(ctx, `
-        INSERT INTO clean_reviews (id, app_id, country, rating, title, content_clean, language, content_en, is_contentful, reviewed_at, response_date, response_content_clean)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+        INSERT INTO clean_reviews (id, app_id, country, rating, title, content_clean, language, content_en, is_contentful, reviewed_at, response_date, response_content_clean, input_hash, pipeline_version, skip_reason, title_en, response_content_en, emojis, is_truncated, original_length, pii_types, spam_score, has_profanity, content_display, content_en_display, duplicate_group_id, canonical_review_id, sentences_clean, sentences_en, elongation_count, is_shouting, sentiment_score, rating_mismatch, language_mix)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,NULLIF($15, ''),$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26::uuid,$27,$28,$29,$30,$31,$32,$33,$34)
 		ON CONFLICT (id) DO UPDATE SET
 			app_id = EXCLUDED.app_id,
 			country = EXCLUDED.country,
@@ -47,18 +157,100 @@ func (r *CleanRepository) UpsertBatch(ctx context.Context, items []CleanReview)
             is_contentful = EXCLUDED.is_contentful,
 			reviewed_at = EXCLUDED.reviewed_at,
 			response_date = EXCLUDED.response_date,
-			response_content_clean = EXCLUDED.response_content_clean`)
+			response_content_clean = EXCLUDED.response_content_clean,
+			input_hash = EXCLUDED.input_hash,
+			pipeline_version = EXCLUDED.pipeline_version,
+			skip_reason = EXCLUDED.skip_reason,
+			title_en = EXCLUDED.title_en,
+			response_content_en = EXCLUDED.response_content_en,
+			emojis = EXCLUDED.emojis,
+			is_truncated = EXCLUDED.is_truncated,
+			original_length = EXCLUDED.original_length,
+			pii_types = EXCLUDED.pii_types,
+			spam_score = EXCLUDED.spam_score,
+			has_profanity = EXCLUDED.has_profanity,
+			content_display = EXCLUDED.content_display,
+			content_en_display = EXCLUDED.content_en_display,
+			duplicate_group_id = EXCLUDED.duplicate_group_id,
+			canonical_review_id = EXCLUDED.canonical_review_id,
+			sentences_clean = EXCLUDED.sentences_clean,
+			sentences_en = EXCLUDED.sentences_en,
+			elongation_count = EXCLUDED.elongation_count,
+			is_shouting = EXCLUDED.is_shouting,
+			sentiment_score = EXCLUDED.sentiment_score,
+			rating_mismatch = EXCLUDED.rating_mismatch,
+			language_mix = EXCLUDED.language_mix,
+			processed_at = NOW()`)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 	defer stmt.Close()
 	for _, it := range items {
-		_, err := stmt.ExecContext(ctx, it.ID, it.AppID, it.Country, it.Rating, it.Title, it.ContentClean, it.Language, it.ContentEN, it.IsContentful, it.ReviewedAt, it.ResponseDate, it.ResponseContentClean)
+		sentClean, err := jsonColumn(it.SentencesClean, it.SentencesClean == nil)
+		if err != nil {
+			return err
+		}
+		sentEN, err := jsonColumn(it.SentencesEN, it.SentencesEN == nil)
+		if err != nil {
+			return err
+		}
+		langMix, err := jsonColumn(it.LanguageMix, it.LanguageMix == nil)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.ExecContext(ctx, it.ID, it.AppID, it.Country, it.Rating, it.Title, it.ContentClean, it.Language, it.ContentEN, it.IsContentful, it.ReviewedAt, it.ResponseDate, it.ResponseContentClean, it.InputHash, it.PipelineVersion, it.SkipReason, it.TitleEN, it.ResponseContentEN, pq.Array(it.Emojis), it.IsTruncated, it.OriginalLength, pq.Array(it.PIITypes), it.SpamScore, it.HasProfanity, it.ContentDisplay, it.ContentENDisplay, it.DuplicateGroupID, it.CanonicalReviewID, sentClean, sentEN, it.ElongationCount, it.IsShouting, it.SentimentScore, it.RatingMismatch, langMix)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 	}
-	return tx.Commit()
+	return nil
+}
+
+// jsonColumn encodes v for a JSONB column, or stores NULL when null is set.
+func jsonColumn(v any, null bool) (any, error) {
+	if null {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// setDuplicateGroups records the group of stored reviews that became the
+// canonical member of a new near-duplicate group.
+func setDuplicateGroups(ctx context.Context, tx *sql.Tx, entries []dedup.Entry) error {
+	for _, e := range entries {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE clean_reviews SET duplicate_group_id = $2::uuid, canonical_review_id = $3
+			WHERE id = $1`, e.ReviewID, e.GroupID, e.CanonicalID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountDuplicates counts the contentful reviews matching f that are
+// non-canonical members of a near-duplicate group whose canonical review also
+// matches f. Duplicates of a review outside the range stand in for it there.
+func (r *CleanRepository) CountDuplicates(ctx context.Context, f RawFilters) (int, error) {
+	var countries any
+	if len(f.Countries) > 0 {
+		countries = pq.Array(f.Countries)
+	}
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM clean_reviews c
+		JOIN clean_reviews k ON k.id = c.canonical_review_id
+		WHERE c.app_id = $1 AND c.is_contentful AND c.canonical_review_id <> c.id
+		AND ($2::text[] IS NULL OR c.country = ANY($2))
+		AND c.reviewed_at >= $3 AND c.reviewed_at <= $4
+		AND k.is_contentful
+		AND ($2::text[] IS NULL OR k.country = ANY($2))
+		AND k.reviewed_at >= $3 AND k.reviewed_at <= $4`,
+		f.AppID, countries, f.DateFrom, f.DateTo).Scan(&n)
+	return n, err
 }
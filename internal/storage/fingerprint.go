@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/lib/pq"
+	"github.com/quiby-ai/review-preprocessor/internal/dedup"
+)
+
+type FingerprintRepository struct{ db *sql.DB }
+
+func NewFingerprintRepository(db *sql.DB) *FingerprintRepository {
+	return &FingerprintRepository{db: db}
+}
+
+// Candidates returns the app's stored fingerprints that have a band matching
+// one of the band probes of fps, which includes every fingerprint within
+// maxDistance bits of one of them; see dedup.Fingerprint.Probes.
+func (r *FingerprintRepository) Candidates(ctx context.Context, appID string, fps []dedup.Fingerprint, maxDistance int) ([]dedup.Entry, error) {
+	if len(fps) == 0 {
+		return nil, nil
+	}
+	var bands [dedup.Bands][]int64
+	var seen [dedup.Bands]map[int]bool
+	for i := range seen {
+		seen[i] = map[int]bool{}
+	}
+	for _, fp := range fps {
+		for i, probes := range fp.Probes(maxDistance) {
+			for _, b := range probes {
+				if !seen[i][b] {
+					seen[i][b] = true
+					bands[i] = append(bands[i], int64(b))
+				}
+			}
+		}
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT review_id, fingerprint, COALESCE(group_id::text, ''), COALESCE(canonical_review_id, '')
+		FROM review_fingerprints
+		WHERE app_id = $1
+		  AND (band0 = ANY($2) OR band1 = ANY($3) OR band2 = ANY($4) OR band3 = ANY($5))`,
+		appID, pq.Array(bands[0]), pq.Array(bands[1]), pq.Array(bands[2]), pq.Array(bands[3]))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []dedup.Entry
+	for rows.Next() {
+		var e dedup.Entry
+		var fp int64
+		if err := rows.Scan(&e.ReviewID, &fp, &e.GroupID, &e.CanonicalID); err != nil {
+			return nil, err
+		}
+		e.Fingerprint = dedup.Fingerprint(fp)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// Duplicates is the near-duplicate bookkeeping of one stored batch.
+type Duplicates struct {
+	AppID string
+	// ReviewIDs are the reprocessed reviews; their stored fingerprints are
+	// replaced by Entries, and those without an entry lose theirs.
+	ReviewIDs []string
+	// Entries are the fingerprinted reviews of the batch.
+	Entries []dedup.Entry
+	// Joined are stored reviews that became canonical of a new group.
+	Joined []dedup.Entry
+}
+
+// saveFingerprints replaces the fingerprints of d.ReviewIDs and upserts those
+// of d.Joined, which is how history rows join a new group.
+func saveFingerprints(ctx context.Context, tx *sql.Tx, d *Duplicates) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM review_fingerprints WHERE review_id = ANY($1)`, pq.Array(d.ReviewIDs)); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO review_fingerprints (review_id, app_id, fingerprint, band0, band1, band2, band3, group_id, canonical_review_id)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8, '')::uuid,NULLIF($9, ''))
+		ON CONFLICT (review_id) DO UPDATE SET
+			app_id = EXCLUDED.app_id,
+			fingerprint = EXCLUDED.fingerprint,
+			band0 = EXCLUDED.band0,
+			band1 = EXCLUDED.band1,
+			band2 = EXCLUDED.band2,
+			band3 = EXCLUDED.band3,
+			group_id = EXCLUDED.group_id,
+			canonical_review_id = EXCLUDED.canonical_review_id,
+			updated_at = NOW()`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range append(slices.Clip(d.Entries), d.Joined...) {
+		b := e.Fingerprint.Bands()
+		_, err := stmt.ExecContext(ctx, e.ReviewID, d.AppID, int64(e.Fingerprint), b[0], b[1], b[2], b[3], e.GroupID, e.CanonicalID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/quiby-ai/review-preprocessor/config"
+)
+
+type OptionsRepository struct{ db *sql.DB }
+
+func NewOptionsRepository(db *sql.DB) *OptionsRepository { return &OptionsRepository{db: db} }
+
+// ErrInvalidOptions wraps options that are stored for an app but cannot be decoded.
+var ErrInvalidOptions = errors.New("invalid processing options")
+
+// Get returns the processing overrides stored for the app.
+// Apps without a row get empty options.
+func (r *OptionsRepository) Get(ctx context.Context, appID string) (config.ProcessingOptions, error) {
+	var opts config.ProcessingOptions
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, `SELECT options FROM app_processing_options WHERE app_id = $1`, appID).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return opts, nil
+	}
+	if err != nil {
+		return opts, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&opts); err != nil {
+		return opts, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+	}
+	return opts, nil
+}
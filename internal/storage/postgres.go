@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 
 	_ "github.com/lib/pq"
@@ -21,6 +22,18 @@ func MustInitPostgres(cfg PostgresConfig) *sql.DB {
 	if err := migrateClean(db); err != nil {
 		log.Fatalf("migrate clean: %v", err)
 	}
+	if err := migrateSagas(db); err != nil {
+		log.Fatalf("migrate sagas: %v", err)
+	}
+	if err := migrateOptions(db); err != nil {
+		log.Fatalf("migrate options: %v", err)
+	}
+	if err := migrateReports(db); err != nil {
+		log.Fatalf("migrate reports: %v", err)
+	}
+	if err := migrateFingerprints(db); err != nil {
+		log.Fatalf("migrate fingerprints: %v", err)
+	}
 	return db
 }
 
@@ -44,11 +57,140 @@ func migrateClean(db *sql.DB) error {
 	if _, err := db.Exec(schema); err != nil {
 		return err
 	}
+	for _, stmt := range []string{
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS input_hash TEXT`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS pipeline_version TEXT`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS skip_reason TEXT
+			CHECK (skip_reason IN ('empty_after_cleaning', 'too_short', 'too_few_words', 'too_few_chars', 'low_alpha_ratio', 'spam', 'duplicate'))`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS title_en TEXT`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS response_content_en TEXT`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS emojis TEXT[]`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS is_truncated BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS original_length INTEGER`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS pii_types TEXT[]`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS spam_score REAL`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS has_profanity BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS content_display TEXT`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS content_en_display TEXT`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS duplicate_group_id UUID`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS canonical_review_id TEXT`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS sentences_clean JSONB`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS sentences_en JSONB`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS elongation_count INTEGER`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS is_shouting BOOLEAN`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS sentiment_score REAL`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS rating_mismatch BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE clean_reviews ADD COLUMN IF NOT EXISTS language_mix JSONB`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
 	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_clean_app_time ON clean_reviews(app_id, reviewed_at);`); err != nil {
 		return err
 	}
 	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_clean_contentful ON clean_reviews(is_contentful);`); err != nil {
 		return err
 	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_clean_skip_reason ON clean_reviews(app_id, skip_reason) WHERE skip_reason IS NOT NULL;`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_clean_rating_mismatch ON clean_reviews(app_id) WHERE rating_mismatch;`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_clean_duplicate_group ON clean_reviews(duplicate_group_id) WHERE duplicate_group_id IS NOT NULL;`); err != nil {
+		return err
+	}
+	return nil
+}
+
+func migrateSagas(db *sql.DB) error {
+	const schema = `
+	CREATE TABLE IF NOT EXISTS preprocess_sagas (
+		saga_id TEXT PRIMARY KEY,
+		app_id TEXT NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		finished_at TIMESTAMPTZ,
+		clean_count INTEGER,
+		reused_count INTEGER,
+		reprocessed_count INTEGER,
+		completed_event JSONB,
+		error TEXT
+	);`
+	_, err := db.Exec(schema)
+	return err
+}
+
+func migrateOptions(db *sql.DB) error {
+	const schema = `
+	CREATE TABLE IF NOT EXISTS app_processing_options (
+		app_id TEXT PRIMARY KEY,
+		options JSONB NOT NULL DEFAULT '{}',
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);`
+	_, err := db.Exec(schema)
+	return err
+}
+
+func migrateReports(db *sql.DB) error {
+	const schema = `
+	CREATE TABLE IF NOT EXISTS preprocess_reports (
+		saga_id TEXT PRIMARY KEY,
+		app_id TEXT NOT NULL,
+		fetched INTEGER NOT NULL,
+		reused INTEGER NOT NULL,
+		reprocessed INTEGER NOT NULL,
+		contentful INTEGER NOT NULL,
+		skipped JSONB NOT NULL,
+		languages JSONB NOT NULL,
+		translation_requested INTEGER NOT NULL,
+		translated INTEGER NOT NULL,
+		translation_failures INTEGER NOT NULL,
+		fallback_uses INTEGER NOT NULL,
+		timings_ms JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE preprocess_reports ADD COLUMN IF NOT EXISTS stage_changes JSONB NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE preprocess_reports ADD COLUMN IF NOT EXISTS duplicates INTEGER NOT NULL DEFAULT 0`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	_, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_reports_app_time ON preprocess_reports(app_id, created_at);`)
+	return err
+}
+
+// review_fingerprints keeps a SimHash per contentful review. The four 16-bit
+// bands are indexed separately so near-duplicate candidates can be found
+// without scanning the app's history.
+func migrateFingerprints(db *sql.DB) error {
+	const schema = `
+	CREATE TABLE IF NOT EXISTS review_fingerprints (
+		review_id TEXT PRIMARY KEY,
+		app_id TEXT NOT NULL,
+		fingerprint BIGINT NOT NULL,
+		band0 INTEGER NOT NULL,
+		band1 INTEGER NOT NULL,
+		band2 INTEGER NOT NULL,
+		band3 INTEGER NOT NULL,
+		group_id UUID,
+		canonical_review_id TEXT,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	for i := range 4 {
+		stmt := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_fingerprints_band%[1]d ON review_fingerprints(app_id, band%[1]d);`, i)
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
 	return nil
 }
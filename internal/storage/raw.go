@@ -2,7 +2,10 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"strconv"
 	"time"
 
 	"github.com/lib/pq"
@@ -31,27 +34,66 @@ type RawReview struct {
 	ResponseContent sql.NullString
 }
 
-func (r *RawRepository) Fetch(ctx context.Context, f RawFilters) ([]RawReview, error) {
+// ContentHash fingerprints every raw field that feeds a clean review, so an
+// unchanged hash means reprocessing would produce the same output.
+func (rr RawReview) ContentHash() string {
+	h := sha256.New()
+	for _, part := range []string{
+		rr.AppID,
+		rr.Country,
+		strconv.Itoa(int(rr.Rating)),
+		rr.Title,
+		rr.Content,
+		rr.ReviewedAt.UTC().Format(time.RFC3339Nano),
+		strconv.FormatBool(rr.ResponseDate.Valid),
+		rr.ResponseDate.Time.UTC().Format(time.RFC3339Nano),
+		strconv.FormatBool(rr.ResponseContent.Valid),
+		rr.ResponseContent.String,
+	} {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RawCursor is a keyset position in the (reviewed_at, id) ordering used by FetchPage.
+type RawCursor struct {
+	ReviewedAt time.Time
+	ID         string
+}
+
+// FetchPage returns up to limit reviews matching f that sort strictly after the cursor.
+// A nil cursor starts at the beginning of the range. Callers advance the cursor to the
+// last returned row until a page shorter than limit comes back.
+func (r *RawRepository) FetchPage(ctx context.Context, f RawFilters, after *RawCursor, limit int) ([]RawReview, error) {
 	q := `SELECT id, app_id, country, rating, title, content, reviewed_at, response_date, response_content
 		FROM raw_reviews
 		WHERE app_id = $1
 		AND ($2::text[] IS NULL OR country = ANY($2))
 		AND reviewed_at >= $3 AND reviewed_at <= $4
-		ORDER BY reviewed_at ASC`
-
-	var rows *sql.Rows
-	var err error
-	if len(f.Countries) == 0 {
-		rows, err = r.db.QueryContext(ctx, q, f.AppID, nil, f.DateFrom, f.DateTo)
-	} else {
-		rows, err = r.db.QueryContext(ctx, q, f.AppID, pq.Array(f.Countries), f.DateFrom, f.DateTo)
+		AND ($5::timestamptz IS NULL OR (reviewed_at, id) > ($5::timestamptz, $6::text))
+		ORDER BY reviewed_at ASC, id ASC
+		LIMIT $7`
+
+	var countries any
+	if len(f.Countries) > 0 {
+		countries = pq.Array(f.Countries)
 	}
+	var afterTime any
+	var afterID string
+	if after != nil {
+		afterTime = after.ReviewedAt
+		afterID = after.ID
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, f.AppID, countries, f.DateFrom, f.DateTo, afterTime, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	out := []RawReview{}
+	out := make([]RawReview, 0, limit)
 	for rows.Next() {
 		var rr RawReview
 		if err := rows.Scan(&rr.ID, &rr.AppID, &rr.Country, &rr.Rating, &rr.Title, &rr.Content, &rr.ReviewedAt, &rr.ResponseDate, &rr.ResponseContent); err != nil {
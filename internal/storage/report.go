@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/quiby-ai/review-preprocessor/internal/report"
+)
+
+type ReportRepository struct{ db *sql.DB }
+
+func NewReportRepository(db *sql.DB) *ReportRepository { return &ReportRepository{db: db} }
+
+// Save stores the saga's report, replacing the one from any earlier attempt.
+func (r *ReportRepository) Save(ctx context.Context, sagaID, appID string, rep *report.Report) error {
+	skipped, err := json.Marshal(rep.Skipped)
+	if err != nil {
+		return err
+	}
+	languages, err := json.Marshal(rep.Languages)
+	if err != nil {
+		return err
+	}
+	stages, err := json.Marshal(rep.StageChanges)
+	if err != nil {
+		return err
+	}
+	timings, err := json.Marshal(rep.TimingsMS)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO preprocess_reports (saga_id, app_id, fetched, reused, reprocessed, contentful, skipped, languages,
+			translation_requested, translated, translation_failures, fallback_uses, timings_ms, stage_changes, duplicates)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+		ON CONFLICT (saga_id) DO UPDATE SET
+			app_id = EXCLUDED.app_id,
+			fetched = EXCLUDED.fetched,
+			reused = EXCLUDED.reused,
+			reprocessed = EXCLUDED.reprocessed,
+			contentful = EXCLUDED.contentful,
+			skipped = EXCLUDED.skipped,
+			languages = EXCLUDED.languages,
+			translation_requested = EXCLUDED.translation_requested,
+			translated = EXCLUDED.translated,
+			translation_failures = EXCLUDED.translation_failures,
+			fallback_uses = EXCLUDED.fallback_uses,
+			timings_ms = EXCLUDED.timings_ms,
+			stage_changes = EXCLUDED.stage_changes,
+			duplicates = EXCLUDED.duplicates,
+			created_at = NOW()`,
+		sagaID, appID, rep.Fetched, rep.Reused, rep.Reprocessed, rep.Contentful, skipped, languages,
+		rep.TranslationRequested, rep.Translated, rep.TranslationFailures, rep.FallbackUses, timings, stages, rep.Duplicates)
+	return err
+}
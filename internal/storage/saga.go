@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type SagaStatus string
+
+const (
+	SagaRunning   SagaStatus = "running"
+	SagaCompleted SagaStatus = "completed"
+	SagaFailed    SagaStatus = "failed"
+)
+
+type SagaRepository struct{ db *sql.DB }
+
+func NewSagaRepository(db *sql.DB) *SagaRepository { return &SagaRepository{db: db} }
+
+// SagaClaim is the outcome of trying to start a saga.
+// When Acquired is false, Status and CompletedEvent describe the existing run.
+type SagaClaim struct {
+	Acquired       bool
+	Status         SagaStatus
+	CompletedEvent json.RawMessage
+}
+
+// SagaCounts are the resulting counts recorded when a saga completes.
+type SagaCounts struct {
+	CleanCount       int
+	ReusedCount      int
+	ReprocessedCount int
+}
+
+// Begin marks the saga as running unless it already completed or another run
+// started less than staleAfter ago. Failed runs and stale running ones (e.g. a
+// crashed pod) are taken over.
+func (r *SagaRepository) Begin(ctx context.Context, sagaID, appID string, staleAfter time.Duration) (SagaClaim, error) {
+	var claimed string
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO preprocess_sagas (saga_id, app_id, status, started_at)
+		VALUES ($1, $2, 'running', NOW())
+		ON CONFLICT (saga_id) DO UPDATE SET
+			status = 'running',
+			started_at = NOW(),
+			finished_at = NULL,
+			error = NULL
+		WHERE preprocess_sagas.status = 'failed'
+			OR (preprocess_sagas.status = 'running' AND preprocess_sagas.started_at < NOW() - make_interval(secs => $3))
+		RETURNING saga_id`, sagaID, appID, staleAfter.Seconds()).Scan(&claimed)
+	if err == nil {
+		return SagaClaim{Acquired: true, Status: SagaRunning}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return SagaClaim{}, err
+	}
+
+	var claim SagaClaim
+	var event []byte
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT status, completed_event FROM preprocess_sagas WHERE saga_id = $1`, sagaID).Scan(&claim.Status, &event); err != nil {
+		return SagaClaim{}, err
+	}
+	claim.CompletedEvent = event
+	return claim, nil
+}
+
+// Complete records the counts and the completion event so a redelivered
+// request can republish it without rerunning the saga.
+func (r *SagaRepository) Complete(ctx context.Context, sagaID string, counts SagaCounts, event json.RawMessage) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE preprocess_sagas SET
+			status = 'completed',
+			finished_at = NOW(),
+			clean_count = $2,
+			reused_count = $3,
+			reprocessed_count = $4,
+			completed_event = $5
+		WHERE saga_id = $1`, sagaID, counts.CleanCount, counts.ReusedCount, counts.ReprocessedCount, []byte(event))
+	return err
+}
+
+func (r *SagaRepository) Fail(ctx context.Context, sagaID string, cause error) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE preprocess_sagas SET
+			status = 'failed',
+			finished_at = NOW(),
+			error = $2
+		WHERE saga_id = $1`, sagaID, cause.Error())
+	return err
+}
@@ -1,64 +1,57 @@
 package textutil
 
 import (
-	"regexp"
 	"strings"
+	"unicode"
 )
 
-var (
-	reHTMLTags   = regexp.MustCompile(`<[^>]+>`) // simple stripper
-	reWhitespace = regexp.MustCompile(`\s+`)
-	// Emoji regex removed due to content corruption issues
-)
-
-func Clean(s string, stripHTML, stripEmoji, normWS bool, maxLen, minLen int) (out string, ok bool) {
-	out = s
-	if stripHTML {
-		out = reHTMLTags.ReplaceAllString(out, " ")
-	}
-	// Emoji stripping disabled due to content corruption issues
-	// if stripEmoji {
-	// 	out = reEmoji.ReplaceAllString(out, " ")
-	// }
-	if normWS {
-		out = reWhitespace.ReplaceAllString(strings.TrimSpace(out), " ")
-	}
-	if maxLen > 0 && len(out) > maxLen {
-		out = out[:maxLen]
-	}
-	if len(out) < minLen {
-		return out, false
+// NormalizeWhitespace trims s and collapses every run of Unicode whitespace to
+// a single space, or to a single newline when the run contains a line break,
+// so paragraph breaks survive.
+func NormalizeWhitespace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inSpace, newline := false, false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			inSpace = true
+			newline = newline || r == '\n'
+			continue
+		}
+		if inSpace && b.Len() > 0 {
+			if newline {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		inSpace, newline = false, false
+		b.WriteRune(r)
 	}
-	return out, true
+	return b.String()
 }
 
 // IsContentful applies simple heuristics to determine if text is contentful.
-// Rules: non-empty, min words or chars, alpha ratio threshold.
+// Rules: non-empty, min words or chars, alpha ratio threshold. Words and
+// characters are measured script-aware; see CountWords and TextLength.
 func IsContentful(text string, minWords, minChars int, minAlphaRatio float64) bool {
-	if len(strings.TrimSpace(text)) == 0 {
-		return false
+	return CheckContentful(text, minWords, minChars, minAlphaRatio) == SkipNone
+}
+
+// CheckContentful applies the IsContentful rules and returns the first one that
+// fails, or SkipNone when text is contentful.
+func CheckContentful(text string, minWords, minChars int, minAlphaRatio float64) SkipReason {
+	if strings.TrimSpace(text) == "" {
+		return SkipEmptyAfterCleaning
 	}
-	if minChars > 0 && len(text) < minChars {
-		return false
+	if minChars > 0 && TextLength(text) < minChars {
+		return SkipTooFewChars
 	}
-	// word count
-	words := reWhitespace.Split(strings.TrimSpace(text), -1)
-	if minWords > 0 && len(words) < minWords {
-		return false
+	if minWords > 0 && CountWords(text) < minWords {
+		return SkipTooFewWords
 	}
-	// alpha ratio
-	if minAlphaRatio > 0 {
-		var alpha int
-		for _, r := range text {
-			if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127 {
-				alpha++
-			}
-		}
-		if total := len(text); total > 0 {
-			if float64(alpha)/float64(total) < minAlphaRatio {
-				return false
-			}
-		}
+	if minAlphaRatio > 0 && AlphaRatio(text) < minAlphaRatio {
+		return SkipLowAlphaRatio
 	}
-	return true
+	return SkipNone
 }
@@ -0,0 +1,226 @@
+package textutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// EmojiMode selects what the emoji stage does with emoji.
+type EmojiMode string
+
+const (
+	// EmojiKeep leaves emoji in place.
+	EmojiKeep EmojiMode = "keep"
+	// EmojiStrip removes emoji.
+	EmojiStrip EmojiMode = "strip"
+	// EmojiShortcode replaces each emoji with its :shortcode:.
+	EmojiShortcode EmojiMode = "shortcode"
+	// EmojiExtract removes emoji from the text and collects them in Doc.Emojis.
+	EmojiExtract EmojiMode = "extract"
+)
+
+// ParseEmojiMode validates a configured emoji mode. An empty string means keep.
+func ParseEmojiMode(s string) (EmojiMode, error) {
+	switch m := EmojiMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return EmojiKeep, nil
+	case EmojiKeep, EmojiStrip, EmojiShortcode, EmojiExtract:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown emoji mode %q", s)
+}
+
+const (
+	zwj            = '\u200D'
+	zwnj           = '\u200C'
+	vs15           = '\uFE0E' // text presentation selector
+	vs16           = '\uFE0F' // emoji presentation selector
+	keycapCombiner = '\u20E3'
+	tagEnd         = '\U000E007F'
+)
+
+// ApplyEmojiMode rewrites s according to mode and returns the emoji it found.
+// Emoji are matched as whole clusters (ZWJ sequences, skin tones, flags,
+// keycaps), so neighbouring characters are never touched.
+func ApplyEmojiMode(s string, mode EmojiMode) (string, []string) {
+	if mode == EmojiKeep || mode == "" {
+		return s, nil
+	}
+	spans := findEmojis(s)
+	if len(spans) == 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	found := make([]string, 0, len(spans))
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(s[last:sp[0]])
+		e := s[sp[0]:sp[1]]
+		found = append(found, e)
+		switch mode {
+		case EmojiShortcode:
+			code := Shortcode(e)
+			if sp[0] > 0 && !isSpaceBefore(s, sp[0]) {
+				code = " " + code
+			}
+			if sp[1] < len(s) && !isSpaceAt(s, sp[1]) {
+				code += " "
+			}
+			b.WriteString(code)
+		default:
+			// a space keeps "great👍app" from collapsing into one word
+			b.WriteByte(' ')
+		}
+		last = sp[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), found
+}
+
+func isSpaceBefore(s string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return r == ' ' || r == '\n' || r == '\t'
+}
+
+func isSpaceAt(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return r == ' ' || r == '\n' || r == '\t'
+}
+
+// findEmojis returns the byte spans of emoji clusters in s.
+func findEmojis(s string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(s); {
+		if end := matchEmoji(s, i); end > i {
+			spans = append(spans, [2]int{i, end})
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return spans
+}
+
+// matchEmoji returns the end of the emoji cluster starting at i, or i if none starts there.
+func matchEmoji(s string, i int) int {
+	r, size := utf8.DecodeRuneInString(s[i:])
+	next := i + size
+
+	// flags are pairs of regional indicators
+	if isRegionalIndicator(r) {
+		if r2, size2 := utf8.DecodeRuneInString(s[next:]); isRegionalIndicator(r2) {
+			return next + size2
+		}
+		return next
+	}
+
+	// keycaps: [0-9#*] FE0F? 20E3
+	if (r >= '0' && r <= '9') || r == '#' || r == '*' {
+		j := next
+		if r2, size2 := utf8.DecodeRuneInString(s[j:]); r2 == vs16 {
+			j += size2
+		}
+		if r2, size2 := utf8.DecodeRuneInString(s[j:]); r2 == keycapCombiner {
+			return j + size2
+		}
+		return i
+	}
+
+	end := matchEmojiElement(s, i)
+	if end == i {
+		return i
+	}
+	// ZWJ sequences: element (ZWJ element)*
+	for {
+		r2, size2 := utf8.DecodeRuneInString(s[end:])
+		if r2 != zwj {
+			return end
+		}
+		more := matchEmojiElement(s, end+size2)
+		if more == end+size2 {
+			return end
+		}
+		end = more
+	}
+}
+
+// matchEmojiElement matches a single pictograph with its presentation
+// selector, skin-tone modifier and tag sequence.
+func matchEmojiElement(s string, i int) int {
+	r, size := utf8.DecodeRuneInString(s[i:])
+	if size == 0 {
+		return i
+	}
+	j := i + size
+	r2, size2 := utf8.DecodeRuneInString(s[j:])
+	switch {
+	case r2 == vs15:
+		// explicitly requested as text
+		return i
+	case r2 == vs16:
+		if !isPictographic(r) && !isTextDefaultPictographic(r) {
+			return i
+		}
+		j += size2
+	case isPictographic(r):
+	default:
+		return i
+	}
+	if r2, size2 := utf8.DecodeRuneInString(s[j:]); isSkinTone(r2) {
+		j += size2
+	}
+	// subdivision flags such as England: 1F3F4 followed by tag characters
+	for {
+		r2, size2 := utf8.DecodeRuneInString(s[j:])
+		if r2 < 0xE0020 || r2 > tagEnd {
+			break
+		}
+		j += size2
+		if r2 == tagEnd {
+			break
+		}
+	}
+	return j
+}
+
+func isRegionalIndicator(r rune) bool { return r >= 0x1F1E6 && r <= 0x1F1FF }
+
+func isSkinTone(r rune) bool { return r >= 0x1F3FB && r <= 0x1F3FF }
+
+// isPictographic reports whether r is shown as emoji without a selector.
+func isPictographic(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF && !isRegionalIndicator(r):
+		return true
+	case r >= 0x2600 && r <= 0x27BF:
+		return true
+	case r >= 0x231A && r <= 0x231B, r >= 0x23E9 && r <= 0x23F3, r >= 0x23F8 && r <= 0x23FA:
+		return true
+	case r == 0x2B50 || r == 0x2B55 || r == 0x2B1B || r == 0x2B1C:
+		return true
+	}
+	return false
+}
+
+// isTextDefaultPictographic reports characters that are ordinary text unless
+// followed by the emoji presentation selector, e.g. © or ↔.
+func isTextDefaultPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9 || r == 0x00AE || r == 0x203C || r == 0x2049 || r == 0x2122 || r == 0x2139:
+		return true
+	case r >= 0x2194 && r <= 0x21AA:
+		return true
+	case r == 0x24C2 || r == 0x25AA || r == 0x25AB || r == 0x25B6 || r == 0x25C0:
+		return true
+	case r >= 0x25FB && r <= 0x25FE:
+		return true
+	case r >= 0x2934 && r <= 0x2935, r >= 0x2B05 && r <= 0x2B07:
+		return true
+	case r == 0x3030 || r == 0x303D || r == 0x3297 || r == 0x3299:
+		return true
+	}
+	return false
+}
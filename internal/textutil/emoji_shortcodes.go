@@ -0,0 +1,108 @@
+package textutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// shortcodes names the emoji most often seen in app reviews, keyed by the
+// cluster with presentation selectors and skin tones removed.
+var shortcodes = map[string]string{
+	"😀": "grinning", "😃": "smiley", "😄": "smile", "😁": "grin", "😆": "laughing",
+	"😅": "sweat_smile", "🤣": "rofl", "😂": "joy", "🙂": "slightly_smiling_face", "🙃": "upside_down_face",
+	"😉": "wink", "😊": "blush", "😇": "innocent", "🥰": "smiling_face_with_hearts", "😍": "heart_eyes",
+	"🤩": "star_struck", "😘": "kissing_heart", "😋": "yum", "😛": "stuck_out_tongue", "😜": "stuck_out_tongue_winking_eye",
+	"🤪": "zany_face", "🤗": "hugs", "🤔": "thinking", "🤐": "zipper_mouth_face", "🤨": "raised_eyebrow",
+	"😐": "neutral_face", "😑": "expressionless", "😶": "no_mouth", "😏": "smirk", "😒": "unamused",
+	"🙄": "roll_eyes", "😬": "grimacing", "😌": "relieved", "😔": "pensive", "😪": "sleepy",
+	"😴": "sleeping", "😷": "mask", "🤒": "face_with_thermometer", "🤢": "nauseated_face", "🤮": "vomiting_face",
+	"🥵": "hot_face", "🥶": "cold_face", "😵": "dizzy_face", "🤯": "exploding_head", "😎": "sunglasses",
+	"🤓": "nerd_face", "😕": "confused", "😟": "worried", "🙁": "slightly_frowning_face", "☹": "frowning_face",
+	"😮": "open_mouth", "😲": "astonished", "😳": "flushed", "🥺": "pleading_face", "😦": "frowning",
+	"😧": "anguished", "😨": "fearful", "😰": "cold_sweat", "😥": "disappointed_relieved", "😢": "cry",
+	"😭": "sob", "😱": "scream", "😖": "confounded", "😣": "persevere", "😞": "disappointed",
+	"😓": "sweat", "😩": "weary", "😫": "tired_face", "🥱": "yawning_face", "😤": "triumph",
+	"😡": "rage", "😠": "angry", "🤬": "cursing_face", "💩": "poop", "🤡": "clown_face",
+	"💀": "skull", "👻": "ghost", "🤖": "robot",
+	"👍": "thumbs_up", "👎": "thumbs_down", "👌": "ok_hand", "✌": "v", "🤞": "crossed_fingers",
+	"👏": "clap", "🙌": "raised_hands", "🙏": "pray", "💪": "muscle", "👋": "wave",
+	"🤝": "handshake", "✋": "raised_hand", "👉": "point_right", "👈": "point_left", "👆": "point_up_2",
+	"👇": "point_down", "🖕": "middle_finger", "🤦": "facepalm", "🤷": "shrug",
+	"❤": "heart", "🧡": "orange_heart", "💛": "yellow_heart", "💚": "green_heart", "💙": "blue_heart",
+	"💜": "purple_heart", "🖤": "black_heart", "🤍": "white_heart", "💔": "broken_heart", "💕": "two_hearts",
+	"💖": "sparkling_heart", "💯": "100", "💥": "boom", "💤": "zzz", "🔥": "fire",
+	"⭐": "star", "🌟": "star2", "✨": "sparkles", "⚡": "zap", "🎉": "tada",
+	"👀": "eyes", "💰": "moneybag", "💸": "money_with_wings", "🐛": "bug", "🚀": "rocket",
+	"⏳": "hourglass_flowing_sand", "⌛": "hourglass", "📱": "iphone", "💻": "computer", "🔋": "battery",
+	"🔒": "lock", "🔑": "key", "📉": "chart_with_downwards_trend", "📈": "chart_with_upwards_trend", "🗑": "wastebasket",
+	"✅": "white_check_mark", "✔": "heavy_check_mark", "❌": "x", "❎": "negative_squared_cross_mark", "⚠": "warning",
+	"🚫": "no_entry_sign", "⛔": "no_entry", "❓": "question", "❗": "exclamation", "‼": "bangbang",
+	"⁉": "interrobang", "🆗": "ok", "🆕": "new", "🆓": "free", "☺": "relaxed",
+	"©": "copyright", "®": "registered", "™": "tm",
+	"👨\u200d👩\u200d👧": "family_man_woman_girl", "👨\u200d👩\u200d👦": "family_man_woman_boy",
+	"❤‍🔥": "heart_on_fire", "❤‍🩹": "mending_heart", "🏳‍🌈": "rainbow_flag", "🏴‍☠": "pirate_flag",
+	"👨‍💻": "man_technologist", "👩‍💻": "woman_technologist", "😮‍💨": "face_exhaling", "😵‍💫": "face_with_spiral_eyes",
+	"🤦‍♂": "man_facepalming", "🤦‍♀": "woman_facepalming", "🤷‍♂": "man_shrugging", "🤷‍♀": "woman_shrugging",
+}
+
+// Shortcode returns the :shortcode: for one emoji cluster. Skin tones become a
+// _toneN suffix, flags use their region code and unknown emoji fall back to
+// the hex code points so the output stays unambiguous.
+func Shortcode(e string) string {
+	var base strings.Builder
+	tone := 0
+	var regions, tags []rune
+	var keycap rune
+	for _, r := range e {
+		switch {
+		case r == vs16 || r == vs15 || r == tagEnd:
+		case r >= 0xE0020 && r < tagEnd:
+			tags = append(tags, r-0xE0000)
+		case isSkinTone(r):
+			tone = int(r-0x1F3FB) + 1
+		case isRegionalIndicator(r):
+			regions = append(regions, 'a'+(r-0x1F1E6))
+		case r == keycapCombiner:
+			keycap = rune(base.String()[0])
+		default:
+			base.WriteRune(r)
+		}
+	}
+	if len(regions) == 2 {
+		return ":flag_" + string(regions) + ":"
+	}
+	// subdivision flags carry their ISO 3166-2 code as tag characters, e.g. gbeng
+	if len(tags) > 2 {
+		return ":flag_" + string(tags[:2]) + "-" + string(tags[2:]) + ":"
+	}
+	if keycap != 0 {
+		switch keycap {
+		case '#':
+			return ":hash:"
+		case '*':
+			return ":asterisk:"
+		}
+		return ":keycap_" + string(keycap) + ":"
+	}
+	key := base.String()
+	name, ok := shortcodes[key]
+	if !ok {
+		name = hexName(key)
+	}
+	if tone > 0 {
+		name = fmt.Sprintf("%s_tone%d", name, tone)
+	}
+	return ":" + name + ":"
+}
+
+func hexName(s string) string {
+	parts := make([]string, 0, utf8.RuneCountInString(s))
+	for _, r := range s {
+		if r == zwj || (r >= 0xE0020 && r <= tagEnd) {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("u%x", r))
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,266 @@
+package textutil
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Emphasis describes how a text was stressed before NormalizeEmphasis
+// toned it down. It is kept as a feature for sentiment analysis.
+type Emphasis struct {
+	// Elongations is the number of stretched letter runs ("sooooo").
+	Elongations int
+	// Shouting is set for text written mostly in capitals.
+	Shouting bool
+}
+
+const (
+	// maxLetterRepeat is how many copies of a stretched letter are kept;
+	// two keeps ordinary double letters ("good", "too") intact.
+	maxLetterRepeat = 2
+	// compoundMinLetters is the word length from which exactly three of a
+	// letter inside the word are kept: compounds such as "Schifffahrt" or
+	// "Kaffeeersatz" spell them that way.
+	compoundMinLetters = 10
+	// shoutingMinLetters is the least number of cased letters a text needs
+	// before it counts as shouting, so "OK" or "WOW" alone does not.
+	shoutingMinLetters = 10
+	// shoutingUpperShare is the share of cased letters that must be upper case.
+	shoutingUpperShare = 0.8
+)
+
+// emphasisPunct are the marks whose repetitions are collapsed.
+const emphasisPunct = "!?.,;:…！？。"
+
+var (
+	// rePlaceholder matches the placeholders left by PII redaction.
+	rePlaceholder = regexp.MustCompile(`\[[A-Z][A-Z_]*\]`)
+	// reHost matches bare host names such as "example.com".
+	reHost = regexp.MustCompile(`^[\p{L}\p{N}\-]+(?:\.[\p{L}\p{N}\-]+)*\.\p{L}{2,}$`)
+	// reRoman matches roman numerals up to 39, as in "Final Fantasy XIII".
+	reRoman = regexp.MustCompile(`^X{0,3}(?:IX|IV|V?I{0,3})$`)
+)
+
+// NormalizeEmphasis collapses letters repeated three or more times to two,
+// runs of ! and ? to one of each, runs of four or more periods to an ellipsis
+// and other repeated punctuation to a single mark. When lowerShouting is set,
+// text that is mostly capitals is lowercased with sentence starts
+// capitalized again. PII placeholders, links, e-mail addresses, host names
+// and roman numerals are left as they are, and so are words in capitals
+// amid text that is not shouting, which are usually acronyms, unless they
+// are stretched themselves.
+func NormalizeEmphasis(text string, lowerShouting bool) (string, Emphasis) {
+	var em Emphasis
+	em.Shouting = isShouting(text)
+	rs := []rune(text)
+	out := make([]rune, 0, len(rs))
+	eachToken(rs, func(tok []rune, space bool) {
+		if space {
+			out = append(out, tok...)
+			return
+		}
+		lead, core, trail := trimTokenPunct(tok)
+		if keepAsIs(string(core), !em.Shouting) {
+			out = append(out, collapseEmphasis(lead, &em)...)
+			out = append(out, core...)
+			out = append(out, collapseEmphasis(trail, &em)...)
+			return
+		}
+		out = append(out, collapseEmphasis(tok, &em)...)
+	})
+	text = string(out)
+	if lowerShouting && em.Shouting {
+		text = unshout(text)
+	}
+	return text, em
+}
+
+// collapseEmphasis tones down the letter and punctuation runs of one token.
+func collapseEmphasis(rs []rune, em *Emphasis) []rune {
+	out := make([]rune, 0, len(rs))
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		j := i + 1
+		switch {
+		case unicode.IsLetter(r):
+			for j < len(rs) && unicode.ToLower(rs[j]) == unicode.ToLower(r) {
+				j++
+			}
+			if j-i > maxLetterRepeat && !compoundTriple(rs, i, j) {
+				em.Elongations++
+				out = append(out, rs[i:i+maxLetterRepeat]...)
+			} else {
+				out = append(out, rs[i:j]...)
+			}
+		case r == '!' || r == '?':
+			for j < len(rs) && (rs[j] == '!' || rs[j] == '?') {
+				j++
+			}
+			out = append(out, collapseMarks(rs[i:j])...)
+		case strings.ContainsRune(emphasisPunct, r):
+			for j < len(rs) && rs[j] == r {
+				j++
+			}
+			switch {
+			case r == '.' && j-i >= 4:
+				out = append(out, '.', '.', '.')
+			case r == '.' && j-i == 3:
+				out = append(out, rs[i:j]...)
+			default:
+				out = append(out, r)
+			}
+		default:
+			out = append(out, r)
+		}
+		i = j
+	}
+	return out
+}
+
+// compoundTriple reports whether the letter run rs[i:j] is exactly three
+// letters inside a word of at least compoundMinLetters letters.
+func compoundTriple(rs []rune, i, j int) bool {
+	if j-i != 3 || i == 0 || j == len(rs) || !unicode.IsLetter(rs[i-1]) || !unicode.IsLetter(rs[j]) {
+		return false
+	}
+	start, end := i, j
+	for start > 0 && unicode.IsLetter(rs[start-1]) {
+		start--
+	}
+	for end < len(rs) && unicode.IsLetter(rs[end]) {
+		end++
+	}
+	return end-start >= compoundMinLetters
+}
+
+// eachToken calls fn for every run of whitespace and of non-whitespace in rs.
+func eachToken(rs []rune, fn func(tok []rune, space bool)) {
+	for i := 0; i < len(rs); {
+		space := unicode.IsSpace(rs[i])
+		j := i + 1
+		for j < len(rs) && unicode.IsSpace(rs[j]) == space {
+			j++
+		}
+		fn(rs[i:j], space)
+		i = j
+	}
+}
+
+// trimTokenPunct splits surrounding punctuation and quotes off a token.
+func trimTokenPunct(tok []rune) (lead, core, trail []rune) {
+	isEdge := func(r rune) bool {
+		return strings.ContainsRune(emphasisPunct, r) || strings.ContainsRune(`"'()«»“”‘’`, r)
+	}
+	i, j := 0, len(tok)
+	for i < j && isEdge(tok[i]) {
+		i++
+	}
+	for j > i && isEdge(tok[j-1]) {
+		j--
+	}
+	return tok[:i], tok[i:j], tok[j:]
+}
+
+// keepAsIs reports whether a token must not be rewritten: a PII placeholder,
+// link, e-mail address, host name or roman numeral, or, when capsAreAcronyms
+// is set, a word written in capitals that is not stretched ("SOOOO").
+func keepAsIs(tok string, capsAreAcronyms bool) bool {
+	switch {
+	case tok == "":
+		return false
+	case rePlaceholder.MatchString(tok),
+		strings.Contains(tok, "://"),
+		strings.HasPrefix(strings.ToLower(tok), "www."),
+		strings.Contains(tok, "@") && strings.Contains(tok, "."),
+		reHost.MatchString(tok),
+		reRoman.MatchString(tok):
+		return true
+	}
+	return capsAreAcronyms && isCapsWord(tok) && !stretched(tok)
+}
+
+// stretched reports whether tok repeats a letter more than maxLetterRepeat
+// times in a row.
+func stretched(tok string) bool {
+	var prev rune
+	run := 0
+	for _, r := range tok {
+		if r == prev && unicode.IsLetter(r) {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		if run > maxLetterRepeat {
+			return true
+		}
+	}
+	return false
+}
+
+// isCapsWord reports whether tok has at least two letters, all in capitals.
+func isCapsWord(tok string) bool {
+	letters := 0
+	for _, r := range tok {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
+
+// collapseMarks keeps the first ! and the first ? of a run in their order,
+// so "!!!" becomes "!" and "?!?!?" becomes "?!".
+func collapseMarks(run []rune) []rune {
+	out := run[:1:1]
+	for _, r := range run[1:] {
+		if r != out[0] {
+			return append(out, r)
+		}
+	}
+	return out
+}
+
+// isShouting reports whether text is mostly capitals, not counting PII
+// placeholders.
+func isShouting(text string) bool {
+	var upper, cased int
+	for _, r := range rePlaceholder.ReplaceAllString(text, " ") {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+			cased++
+		case unicode.IsLower(r):
+			cased++
+		}
+	}
+	return cased >= shoutingMinLetters && float64(upper) >= shoutingUpperShare*float64(cased)
+}
+
+// unshout lowercases text and capitalizes the first letter of each sentence.
+// Tokens kept as-is by NormalizeEmphasis keep their case.
+func unshout(text string) string {
+	rs := []rune(text)
+	lowered := make([]rune, 0, len(rs))
+	eachToken(rs, func(tok []rune, space bool) {
+		if !space {
+			if _, core, _ := trimTokenPunct(tok); !keepAsIs(string(core), false) {
+				tok = []rune(strings.ToLower(string(tok)))
+			}
+		}
+		lowered = append(lowered, tok...)
+	})
+	rs = lowered
+	for _, sp := range Sentences(string(rs), "") {
+		for i := sp.Start; i < sp.End; i++ {
+			if unicode.IsLetter(rs[i]) {
+				rs[i] = unicode.ToUpper(rs[i])
+				break
+			}
+		}
+	}
+	return string(rs)
+}
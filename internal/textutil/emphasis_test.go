@@ -0,0 +1,68 @@
+package textutil
+
+import "testing"
+
+func TestNormalizeEmphasis(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		lower       bool
+		want        string
+		elongations int
+		shouting    bool
+	}{
+		{"plain", "Good app, too slow", true, "Good app, too slow", 0, false},
+		{"elongation", "soooo goooood", true, "soo good", 2, false},
+		{"word end", "yesss omggg", true, "yess omgg", 2, false},
+		{"punctuation", "why?!?!?! great!!! wait.... ok,,,", true, "why?! great! wait... ok,", 0, false},
+		{"ellipsis kept", "well... fine", true, "well... fine", 0, false},
+		{"www url", "see www.example.com for more", true, "see www.example.com for more", 0, false},
+		{"http url", "https://aaa.example.com/xxx!!!", true, "https://aaa.example.com/xxx!", 0, false},
+		{"email", "mail ooo@example.org please", true, "mail ooo@example.org please", 0, false},
+		{"host", "try booook.com instead", true, "try booook.com instead", 0, false},
+		{"roman numeral", "Final Fantasy III is great", true, "Final Fantasy III is great", 0, false},
+		{"roman numeral in quotes", "loved (XXX) and \"VIII\"!!", true, "loved (XXX) and \"VIII\"!", 0, false},
+		{"acronym", "works with NFC and the GPS", true, "works with NFC and the GPS", 0, false},
+		{"stretched caps word", "SOOOOO bad", true, "SOO bad", 1, false},
+		{"stretched caps in prose", "This app is SOOOOO bad, NOOOO!!!", true, "This app is SOO bad, NOO!", 2, false},
+		{"compound", "Die Schifffahrt und Brennnessel", true, "Die Schifffahrt und Brennnessel", 0, false},
+		{"compound vowel", "Kaffeeersatz schmeckt gut", true, "Kaffeeersatz schmeckt gut", 0, false},
+		{"short word triple", "goood", true, "good", 1, false},
+		{"long run in long word", "amaaaaaazing app", true, "amaazing app", 1, false},
+		{"shouting", "THIS APP IS TERRIBLE. FIX IT", true, "This app is terrible. Fix it", 0, true},
+		{"shouting kept", "THIS APP IS TERRIBLE", false, "THIS APP IS TERRIBLE", 0, true},
+		{"shouting elongation", "SOOOO BAAAAD APP", true, "Soo baad app", 2, true},
+		{"shouting keeps numeral", "FINAL FANTASY III IS GREAT", true, "Final fantasy III is great", 0, true},
+		{"shouting keeps placeholder", "WRITE TO [EMAIL] NOW PLEASE", true, "Write to [EMAIL] now please", 0, true},
+		{"placeholders are not shouting", "ok [EMAIL] [PHONE] [ORDER_ID]", true, "ok [EMAIL] [PHONE] [ORDER_ID]", 0, false},
+		{"short caps", "WOW", true, "WOW", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, em := NormalizeEmphasis(tt.in, tt.lower)
+			if got != tt.want || em.Elongations != tt.elongations || em.Shouting != tt.shouting {
+				t.Errorf("NormalizeEmphasis(%q, %v) = %q, %+v; want %q, {Elongations:%d Shouting:%v}",
+					tt.in, tt.lower, got, em, tt.want, tt.elongations, tt.shouting)
+			}
+		})
+	}
+}
+
+// With pii ahead of emphasis, as configured, stretched-looking links are
+// redacted before emphasis could rewrite them.
+func TestEmphasisAfterPII(t *testing.T) {
+	p, err := BuildPipeline([]StageSpec{
+		{Name: StagePII, Params: map[string]any{"detectors": []any{"email", "url"}}},
+		{Name: StageEmphasis, Params: map[string]any{"lowercase_shouting": true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := p.Run("VISIT WWW.GOOOGLE.COM OR MAIL ME AT BOB@EXAMPLE.COM", "us")
+	if want := "Visit [URL] or mail me at [EMAIL]"; d.Text != want {
+		t.Errorf("Run() = %q, want %q", d.Text, want)
+	}
+	if d.Emphasis == nil || !d.Emphasis.Shouting || d.Emphasis.Elongations != 0 {
+		t.Errorf("Emphasis = %+v", d.Emphasis)
+	}
+}
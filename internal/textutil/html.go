@@ -0,0 +1,114 @@
+package textutil
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skipElements hold no readable review text; their contents are dropped.
+var skipElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Head:     true,
+	atom.Title:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Svg:      true,
+}
+
+// blockElements start on a new line when rendered.
+var blockElements = map[atom.Atom]bool{
+	atom.Br:         true,
+	atom.P:          true,
+	atom.Div:        true,
+	atom.Li:         true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Tr:         true,
+	atom.Table:      true,
+	atom.Blockquote: true,
+	atom.Pre:        true,
+	atom.Hr:         true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Section:    true,
+	atom.Article:    true,
+	atom.Header:     true,
+	atom.Footer:     true,
+}
+
+// StripHTML converts an HTML fragment to plain text. Entities are decoded,
+// block elements become line breaks and non-content elements such as
+// <script> and <style> are dropped with their contents. Anything that is not
+// a known HTML tag, e.g. the brackets in "a <3 b > c", is kept verbatim, and
+// so is a known tag name followed by words that are not HTML attributes, as
+// in the comparison "if a<b and c>d".
+func StripHTML(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	skip := 0
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			// io.EOF; an unterminated "<..." at the end is left in Raw and is text
+			if skip == 0 {
+				b.WriteString(html.UnescapeString(string(z.Raw())))
+			}
+			return b.String()
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			raw := string(z.Raw())
+			name, hasAttr := z.TagName()
+			a := atom.Lookup(name)
+			if a == 0 || hasAttr && !knownAttrs(z) {
+				// not a real tag, just text that happens to contain '<'
+				if skip == 0 {
+					b.WriteString(html.UnescapeString(raw))
+				}
+				continue
+			}
+			if skipElements[a] {
+				switch tt {
+				case html.StartTagToken:
+					skip++
+				case html.EndTagToken:
+					if skip > 0 {
+						skip--
+					}
+				}
+				continue
+			}
+			if skip == 0 && blockElements[a] {
+				b.WriteByte('\n')
+			}
+		}
+		// comments and doctypes are dropped
+	}
+}
+
+// knownAttrs reports whether all remaining attributes of the current tag are
+// HTML attributes, counting data-* and aria-* ones.
+func knownAttrs(z *html.Tokenizer) bool {
+	for more := true; more; {
+		var key []byte
+		key, _, more = z.TagAttr()
+		k := string(key)
+		if atom.Lookup(key) == 0 && !strings.HasPrefix(k, "data-") && !strings.HasPrefix(k, "aria-") {
+			return false
+		}
+	}
+	return true
+}
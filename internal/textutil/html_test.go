@@ -0,0 +1,31 @@
+package textutil
+
+import "testing"
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain text", "Great app", "Great app"},
+		{"inline tags", "<b>Great</b> <i>app</i>", "Great app"},
+		{"entities", "Tom &amp; Jerry &lt;3", "Tom & Jerry <3"},
+		{"block elements", "<p>one</p><p>two</p>", "\none\n\ntwo\n"},
+		{"line break", "one<br>two<br/>three", "one\ntwo\nthree"},
+		{"script dropped", "hi<script>alert(1)</script> there", "hi there"},
+		{"nested skip", "<style>a{}<svg>x</svg>b{}</style>ok", "ok"},
+		{"comment dropped", "a<!-- hidden -->b", "ab"},
+		{"heart and arrow", "a <3 b > c", "a <3 b > c"},
+		{"unknown tag kept", "I <love> it", "I <love> it"},
+		{"comparison", "if a<b and c>d", "if a<b and c>d"},
+		{"tag with attributes", `<a href="x" class="y" data-id="1">link</a> <p aria-label="z">text</p>`, "link \ntext\n"},
+		{"unterminated tag", "ends with <b", "ends with <b"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripHTML(tt.in); got != tt.want {
+				t.Errorf("StripHTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
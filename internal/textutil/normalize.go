@@ -0,0 +1,148 @@
+package textutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// NormForm is the Unicode normalization form applied by Normalize.
+type NormForm string
+
+const (
+	NormNone NormForm = "none"
+	NormNFC  NormForm = "NFC"
+	NormNFKC NormForm = "NFKC"
+)
+
+// ParseNormForm validates a configured normalization form. An empty string means none.
+func ParseNormForm(s string) (NormForm, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", "NONE":
+		return NormNone, nil
+	case "NFC":
+		return NormNFC, nil
+	case "NFKC":
+		return NormNFKC, nil
+	}
+	return "", fmt.Errorf("unknown normalization form %q", s)
+}
+
+// NormalizeOptions configure Normalize.
+type NormalizeOptions struct {
+	Form NormForm
+	// StripInvisible removes zero-width, bidi and other format or control
+	// characters that carry no visible content.
+	StripInvisible bool
+	// FoldConfusables maps full-width forms to ASCII and, inside words that
+	// mix scripts, Cyrillic and Greek lookalikes to their Latin twins.
+	FoldConfusables bool
+}
+
+// Normalize brings s to a canonical form so equal-looking reviews compare
+// equal and language detection sees ordinary letters.
+func Normalize(s string, o NormalizeOptions) string {
+	switch o.Form {
+	case NormNFC:
+		s = norm.NFC.String(s)
+	case NormNFKC:
+		s = norm.NFKC.String(s)
+	}
+	if o.StripInvisible {
+		s = stripInvisible(s)
+	}
+	if o.FoldConfusables {
+		s = foldConfusables(width.Fold.String(s))
+	}
+	return s
+}
+
+// stripInvisible drops format and control characters. Joiners inside emoji
+// clusters and between letters (Persian, Indic scripts) are kept, as are
+// newlines and tabs.
+func stripInvisible(s string) string {
+	spans := findEmojis(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	prev := rune(0)
+	for i, r := range s {
+		for len(spans) > 0 && spans[0][1] <= i {
+			spans = spans[1:]
+		}
+		inEmoji := len(spans) > 0 && i >= spans[0][0]
+		switch {
+		case inEmoji, r == '\n', r == '\t':
+		case unicode.Is(unicode.Cc, r):
+			continue
+		case unicode.Is(unicode.Cf, r):
+			if (r == zwnj || r == zwj) && unicode.IsLetter(prev) && unicode.IsLetter(nextRune(s, i+len(string(r)))) {
+				break
+			}
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
+
+func nextRune(s string, i int) rune {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return r
+}
+
+// latinLookalikes maps Cyrillic and Greek letters to the Latin letters they
+// are visually indistinguishable from.
+var latinLookalikes = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't',
+	'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T',
+	'У': 'Y', 'Х': 'X', 'І': 'I', 'Ј': 'J', 'Ѕ': 'S',
+	// Greek
+	'α': 'a', 'ο': 'o', 'ν': 'v', 'ι': 'i', 'κ': 'k', 'τ': 't', 'υ': 'u', 'ρ': 'p',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O',
+	'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+}
+
+// foldConfusables rewrites lookalike letters in words that also contain
+// Latin letters, e.g. "рaypal" spelled with a Cyrillic "р". Words written
+// entirely in Cyrillic or Greek are left alone.
+func foldConfusables(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	word := make([]rune, 0, 16)
+	flush := func() {
+		hasLatin, hasLookalike := false, false
+		for _, r := range word {
+			if unicode.Is(unicode.Latin, r) {
+				hasLatin = true
+			} else if _, ok := latinLookalikes[r]; ok {
+				hasLookalike = true
+			}
+		}
+		for _, r := range word {
+			if hasLatin && hasLookalike {
+				if l, ok := latinLookalikes[r]; ok {
+					r = l
+				}
+			}
+			b.WriteRune(r)
+		}
+		word = word[:0]
+	}
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.Is(unicode.M, r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
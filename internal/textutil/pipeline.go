@@ -0,0 +1,194 @@
+package textutil
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// Doc is a text moving through a Pipeline together with what the stages
+// learned about it.
+type Doc struct {
+	Text string
+	// Locale is the review's ISO 3166 country code and may be empty.
+	Locale string
+	// Truncated is set when a truncate stage cut Text; OriginalLen is the
+	// length in runes before the cut, or of the final Text otherwise.
+	Truncated   bool
+	OriginalLen int
+	Emojis      []string
+	PII         []PIIType
+	// Emphasis is set by an emphasis stage and nil when none ran.
+	Emphasis *Emphasis
+	Trace    []StageTrace
+}
+
+// StageTrace records the effect of one stage on a Doc. Lengths are in runes.
+type StageTrace struct {
+	Stage   string `json:"stage"`
+	Changed bool   `json:"changed"`
+	Before  int    `json:"before"`
+	After   int    `json:"after"`
+}
+
+// Stage is one step of a cleaning Pipeline. Stages must be safe for
+// concurrent use; all per-text state lives in the Doc.
+type Stage interface {
+	Name() string
+	Apply(d *Doc)
+}
+
+// Pipeline runs an ordered list of stages over a text.
+type Pipeline struct {
+	stages []Stage
+}
+
+func NewPipeline(stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Run cleans text and traces every stage.
+func (p *Pipeline) Run(text, locale string) Doc {
+	d := Doc{Text: text, Locale: locale, Trace: make([]StageTrace, 0, len(p.stages))}
+	for _, st := range p.stages {
+		before, n := d.Text, utf8.RuneCountInString(d.Text)
+		st.Apply(&d)
+		d.Trace = append(d.Trace, StageTrace{
+			Stage:   st.Name(),
+			Changed: d.Text != before,
+			Before:  n,
+			After:   utf8.RuneCountInString(d.Text),
+		})
+	}
+	if !d.Truncated {
+		d.OriginalLen = utf8.RuneCountInString(d.Text)
+	}
+	return d
+}
+
+// Stages returns the stage names in order.
+func (p *Pipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, st := range p.stages {
+		names[i] = st.Name()
+	}
+	return names
+}
+
+// StageSpec configures one pipeline stage by name. Params values are what the
+// config decoder produced: strings, bools, integers, floats or lists of those.
+type StageSpec struct {
+	Name   string
+	Params map[string]any
+}
+
+// Int returns the named integer parameter, or def when it is not set.
+func (s StageSpec) Int(key string, def int) (int, error) {
+	return stageParams(s.Params).int(key, def)
+}
+
+// WithParam returns a copy of s with key set to v; s itself is not modified.
+func (s StageSpec) WithParam(key string, v any) StageSpec {
+	params := make(map[string]any, len(s.Params)+1)
+	for k, pv := range s.Params {
+		params[k] = pv
+	}
+	params[key] = v
+	s.Params = params
+	return s
+}
+
+// BuildPipeline builds the stages described by specs, in order. Unknown
+// stages and unknown or ill-typed parameters are errors.
+func BuildPipeline(specs []StageSpec) (*Pipeline, error) {
+	stages := make([]Stage, 0, len(specs))
+	for i, spec := range specs {
+		build, ok := stageBuilders[spec.Name]
+		if !ok {
+			return nil, fmt.Errorf("stage %d: unknown stage %q", i, spec.Name)
+		}
+		st, err := build(stageParams(spec.Params))
+		if err != nil {
+			return nil, fmt.Errorf("stage %d (%s): %w", i, spec.Name, err)
+		}
+		stages = append(stages, st)
+	}
+	return NewPipeline(stages...), nil
+}
+
+// stageParams reads typed values out of a StageSpec's Params.
+type stageParams map[string]any
+
+// only rejects parameters outside keys, which are usually typos.
+func (p stageParams) only(keys ...string) error {
+	for k := range p {
+		if !slices.Contains(keys, k) {
+			return fmt.Errorf("unknown parameter %q", k)
+		}
+	}
+	return nil
+}
+
+func (p stageParams) string(key, def string) (string, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %T", key, v)
+	}
+	return s, nil
+}
+
+func (p stageParams) bool(key string, def bool) (bool, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean, got %T", key, v)
+	}
+	return b, nil
+}
+
+func (p stageParams) int(key string, def int) (int, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), nil
+		}
+	}
+	return 0, fmt.Errorf("%s must be an integer, got %v", key, v)
+}
+
+func (p stageParams) strings(key string, def []string) ([]string, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	switch l := v.(type) {
+	case []string:
+		return l, nil
+	case []any:
+		out := make([]string, len(l))
+		for i, e := range l {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings, got %T element", key, e)
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s must be a list of strings, got %T", key, v)
+}
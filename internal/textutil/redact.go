@@ -0,0 +1,250 @@
+package textutil
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PIIType names a kind of personal data; it doubles as the placeholder text.
+type PIIType string
+
+const (
+	PIIEmail   PIIType = "EMAIL"
+	PIIPhone   PIIType = "PHONE"
+	PIIURL     PIIType = "URL"
+	PIICard    PIIType = "CARD"
+	PIIOrderID PIIType = "ORDER_ID"
+)
+
+// Placeholder is the text a match of this type is replaced with.
+func (t PIIType) Placeholder() string { return "[" + string(t) + "]" }
+
+// Detector finds one kind of personal data. locale is the review's ISO 3166
+// country code and may be empty.
+type Detector interface {
+	Type() PIIType
+	Find(text, locale string) [][2]int
+}
+
+// Redactor replaces personal data with typed placeholders.
+type Redactor struct {
+	detectors []Detector
+}
+
+// NewRedactor builds a redactor. When matches overlap, the detector listed
+// first wins, so more specific detectors should come first.
+func NewRedactor(detectors ...Detector) *Redactor {
+	return &Redactor{detectors: detectors}
+}
+
+// DetectorsByName returns the built-in detectors in the given order.
+// Known names: email, url, card, phone, order_id.
+func DetectorsByName(names []string) ([]Detector, error) {
+	out := make([]Detector, 0, len(names))
+	for _, n := range names {
+		switch strings.ToLower(strings.TrimSpace(n)) {
+		case "email":
+			out = append(out, EmailDetector{})
+		case "url":
+			out = append(out, URLDetector{})
+		case "card":
+			out = append(out, CardDetector{})
+		case "phone":
+			out = append(out, PhoneDetector{})
+		case "order_id":
+			out = append(out, OrderIDDetector{})
+		default:
+			return nil, fmt.Errorf("unknown PII detector %q", n)
+		}
+	}
+	return out, nil
+}
+
+type piiMatch struct {
+	span [2]int
+	typ  PIIType
+	rank int
+}
+
+// Redact replaces every detected item in text and returns the distinct types found.
+func (r *Redactor) Redact(text, locale string) (string, []PIIType) {
+	if r == nil || len(r.detectors) == 0 || text == "" {
+		return text, nil
+	}
+	var matches []piiMatch
+	for rank, d := range r.detectors {
+		for _, sp := range d.Find(text, locale) {
+			matches = append(matches, piiMatch{span: sp, typ: d.Type(), rank: rank})
+		}
+	}
+	if len(matches) == 0 {
+		return text, nil
+	}
+	// earlier detectors claim their spans first
+	slices.SortStableFunc(matches, func(a, b piiMatch) int { return a.rank - b.rank })
+	kept := make([]piiMatch, 0, len(matches))
+	for _, m := range matches {
+		if !slices.ContainsFunc(kept, func(k piiMatch) bool { return m.span[0] < k.span[1] && k.span[0] < m.span[1] }) {
+			kept = append(kept, m)
+		}
+	}
+	slices.SortFunc(kept, func(a, b piiMatch) int { return a.span[0] - b.span[0] })
+
+	var b strings.Builder
+	b.Grow(len(text))
+	var types []PIIType
+	last := 0
+	for _, m := range kept {
+		b.WriteString(text[last:m.span[0]])
+		b.WriteString(m.typ.Placeholder())
+		last = m.span[1]
+		if !slices.Contains(types, m.typ) {
+			types = append(types, m.typ)
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String(), types
+}
+
+var (
+	reEmail = regexp.MustCompile(`[\p{L}\p{N}._%+\-]+@[\p{L}\p{N}\-]+(?:\.[\p{L}\p{N}\-]+)*\.\p{L}{2,}`)
+	reURL   = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'()\[\]]+[^\s<>"'()\[\].,;:!?]`)
+	reCard  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// Only the keywords are case-insensitive; identifiers are upper case and digits.
+	reOrderID = regexp.MustCompile(`(?:^|[^\p{L}\p{N}])(?i:order|invoice|transaction|receipt|ticket|case|bestellung|pedido|commande|ordine|заказ)(?i:\s*(?:id|no\.?|number|nr\.?|num|номер))?\s*[:#№]?\s*([A-Z0-9][A-Z0-9\-]{5,})`)
+	// reDate matches dates such as "2024-05-11" or "11.05.24".
+	reDate = regexp.MustCompile(`^\d{1,4}[-./]\d{1,2}[-./]\d{1,4}$`)
+)
+
+type EmailDetector struct{}
+
+func (EmailDetector) Type() PIIType { return PIIEmail }
+
+func (EmailDetector) Find(text, _ string) [][2]int {
+	return spans(reEmail.FindAllStringIndex(text, -1))
+}
+
+type URLDetector struct{}
+
+func (URLDetector) Type() PIIType { return PIIURL }
+
+func (URLDetector) Find(text, _ string) [][2]int { return spans(reURL.FindAllStringIndex(text, -1)) }
+
+// CardDetector finds 13–19 digit numbers that pass the Luhn check.
+type CardDetector struct{}
+
+func (CardDetector) Type() PIIType { return PIICard }
+
+func (CardDetector) Find(text, _ string) [][2]int {
+	var out [][2]int
+	for _, m := range reCard.FindAllStringIndex(text, -1) {
+		if luhnValid(text[m[0]:m[1]]) {
+			out = append(out, [2]int{m[0], m[1]})
+		}
+	}
+	return out
+}
+
+// luhnValid checks the Luhn checksum over the digits in s.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// phonePatterns hold national formats keyed by country; reIntlPhone covers
+// numbers written with a country code everywhere.
+var (
+	phonePatterns = map[string]*regexp.Regexp{
+		"us": regexp.MustCompile(`(?:\+?1[\s.\-]?)?\(?[2-9]\d{2}\)?[\s.\-]?\d{3}[\s.\-]?\d{4}\b`),
+		"ca": regexp.MustCompile(`(?:\+?1[\s.\-]?)?\(?[2-9]\d{2}\)?[\s.\-]?\d{3}[\s.\-]?\d{4}\b`),
+		"gb": regexp.MustCompile(`\b0\d{2,4}[\s\-]?\d{3,4}[\s\-]?\d{3,4}\b`),
+		"de": regexp.MustCompile(`\b0[1-9]\d{1,4}[\s/\-]?\d{3,8}\b`),
+		"fr": regexp.MustCompile(`\b0[1-9](?:[\s.\-]?\d{2}){4}\b`),
+		"es": regexp.MustCompile(`\b[6789]\d{2}[\s\-]?\d{3}[\s\-]?\d{3}\b`),
+		"it": regexp.MustCompile(`\b3\d{2}[\s\-]?\d{6,7}\b`),
+		"ru": regexp.MustCompile(`(?:\b8|\+7)[\s\-]?\(?\d{3}\)?[\s\-]?\d{3}[\s\-]?\d{2}[\s\-]?\d{2}\b`),
+		"in": regexp.MustCompile(`\b[6-9]\d{4}[\s\-]?\d{5}\b`),
+		"br": regexp.MustCompile(`\(?\b\d{2}\)?[\s\-]?9\d{4}[\s\-]?\d{4}\b`),
+		"jp": regexp.MustCompile(`\b0\d{1,4}-\d{1,4}-\d{4}\b`),
+	}
+	reIntlPhone = regexp.MustCompile(`\+\d{1,3}[\s.\-]?\(?\d{1,4}\)?(?:[\s.\-]?\d{2,4}){2,4}\b`)
+)
+
+// PhoneDetector finds international numbers and the national formats of the
+// review's country.
+type PhoneDetector struct{}
+
+func (PhoneDetector) Type() PIIType { return PIIPhone }
+
+func (PhoneDetector) Find(text, locale string) [][2]int {
+	var out [][2]int
+	add := func(ms [][]int) {
+		for _, m := range ms {
+			if n := countDigits(text[m[0]:m[1]]); n >= 7 && n <= 15 {
+				out = append(out, [2]int{m[0], m[1]})
+			}
+		}
+	}
+	add(reIntlPhone.FindAllStringIndex(text, -1))
+	if re, ok := phonePatterns[strings.ToLower(locale)]; ok {
+		add(re.FindAllStringIndex(text, -1))
+	}
+	return out
+}
+
+// OrderIDDetector finds identifiers introduced by words like "order" or
+// "invoice"; only the identifier is redacted.
+type OrderIDDetector struct{}
+
+func (OrderIDDetector) Type() PIIType { return PIIOrderID }
+
+func (OrderIDDetector) Find(text, _ string) [][2]int {
+	var out [][2]int
+	for _, m := range reOrderID.FindAllStringSubmatchIndex(text, -1) {
+		id := text[m[2]:m[3]]
+		// require a digit so ordinary words after "order" survive, skip dates
+		// ("ticket 2024-05-11") and identifiers running on into lower case
+		next, _ := utf8.DecodeRuneInString(text[m[3]:])
+		if countDigits(id) > 0 && !reDate.MatchString(id) && !unicode.IsLetter(next) {
+			out = append(out, [2]int{m[2], m[3]})
+		}
+	}
+	return out
+}
+
+func countDigits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+func spans(ms [][]int) [][2]int {
+	out := make([][2]int, len(ms))
+	for i, m := range ms {
+		out[i] = [2]int{m[0], m[1]}
+	}
+	return out
+}
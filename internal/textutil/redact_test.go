@@ -0,0 +1,66 @@
+package textutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	detectors, err := DetectorsByName([]string{"email", "url", "card", "phone", "order_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRedactor(detectors...)
+	tests := []struct {
+		name, in, locale, want string
+		types                  []PIIType
+	}{
+		{"nothing to redact", "Great app, 5 stars", "us", "Great app, 5 stars", nil},
+		{"email", "write to jane.doe@example.com please", "", "write to [EMAIL] please", []PIIType{PIIEmail}},
+		{"url", "see www.example.com/help.", "", "see [URL].", []PIIType{PIIURL}},
+		{"luhn valid card", "charged 4111 1111 1111 1111 twice", "", "charged [CARD] twice", []PIIType{PIICard}},
+		{"luhn invalid card", "ref 4111 1111 1111 1112 here", "", "ref 4111 1111 1111 1112 here", nil},
+		{"dashed card", "card 5500-0000-0000-0004", "", "card [CARD]", []PIIType{PIICard}},
+		{"international phone", "call +44 20 7946 0958 now", "", "call [PHONE] now", []PIIType{PIIPhone}},
+		{"us phone", "call (415) 555-2671 now", "us", "call [PHONE] now", []PIIType{PIIPhone}},
+		{"national format needs locale", "call (415) 555-2671 now", "de", "call (415) 555-2671 now", nil},
+		{"german phone", "Ruf 030 1234567 an", "de", "Ruf [PHONE] an", []PIIType{PIIPhone}},
+		{"too few digits", "version +1 2 3", "", "version +1 2 3", nil},
+		{"order id", "order #AB12345 never came", "", "order #[ORDER_ID] never came", []PIIType{PIIOrderID}},
+		{"order word without digits", "order ANYTHING online", "", "order ANYTHING online", nil},
+		{"keyword in any case", "Invoice No. INV-2024-0042 is wrong", "", "Invoice No. [ORDER_ID] is wrong", []PIIType{PIIOrderID}},
+		{"date after keyword", "ticket 2024-05-11 still open", "", "ticket 2024-05-11 still open", nil},
+		{"short date after keyword", "order 11.05.2024 late", "", "order 11.05.2024 late", nil},
+		{"lower-case word after keyword", "case number abcdef closed", "", "case number abcdef closed", nil},
+		{"lower-case word with digits", "ordered 3 items, order later2day", "", "ordered 3 items, order later2day", nil},
+		{"identifier running into lower case", "order AB12345abc", "", "order AB12345abc", nil},
+		{"several types", "mail a@b.io or +1 415 555 2671", "", "mail [EMAIL] or [PHONE]", []PIIType{PIIEmail, PIIPhone}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, types := r.Redact(tt.in, tt.locale)
+			if got != tt.want || !slices.Equal(types, tt.types) {
+				t.Errorf("Redact(%q, %q) = %q, %v; want %q, %v", tt.in, tt.locale, got, types, tt.want, tt.types)
+			}
+		})
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"4111111111111111", true},
+		{"4111111111111112", false},
+		{"4111 1111 1111 1111", true},
+		{"378282246310005", true},
+		{"79927398713", false}, // valid checksum but too short for a card
+		{"0000000000000", true},
+	}
+	for _, tt := range tests {
+		if got := luhnValid(tt.in); got != tt.want {
+			t.Errorf("luhnValid(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
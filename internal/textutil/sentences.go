@@ -0,0 +1,201 @@
+package textutil
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Span is a half-open range of rune offsets into a text. It encodes to JSON
+// as a [start, end] pair; rune offsets match Postgres substring() and most
+// languages' character indexing.
+type Span struct {
+	Start, End int
+}
+
+func (s Span) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{s.Start, s.End})
+}
+
+func (s *Span) UnmarshalJSON(b []byte) error {
+	var p [2]int
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	s.Start, s.End = p[0], p[1]
+	return nil
+}
+
+// abbreviations end with a period that does not end a sentence, keyed by
+// ISO 639-1 code. Entries are lowercase and without the final period.
+var abbreviations = map[string][]string{
+	"en": {"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "vs", "etc", "e.g", "i.e", "approx", "inc", "ltd", "jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec", "a.m", "p.m", "u.s"},
+	"de": {"z.b", "bzw", "usw", "ca", "dr", "nr", "str", "evtl", "ggf", "inkl", "d.h", "u.a", "z.t", "mind", "max", "min"},
+	"fr": {"m", "mme", "mlle", "dr", "etc", "p.ex", "cf", "env", "min", "max"},
+	"es": {"sr", "sra", "srta", "dr", "dra", "etc", "p.ej", "aprox", "núm", "min", "max"},
+	"it": {"sig", "sig.ra", "dott", "ecc", "es", "ca", "min", "max"},
+	"pt": {"sr", "sra", "dr", "dra", "etc", "p.ex", "aprox", "min", "max"},
+	"ru": {"т.е", "т.д", "т.п", "т.к", "г", "гг", "др", "им", "ул", "мин", "макс", "руб"},
+}
+
+// wordAbbreviations are also ordinary words ("Would I recommend it? No."),
+// so they only count as abbreviations before what they usually precede: a
+// number as in "No. 5" or a name as in "St. Louis".
+var wordAbbreviations = map[string]map[string]func(next []rune) bool{
+	"en": {"no": startsWithDigit, "st": isName},
+}
+
+// sentenceClosers may follow a terminator and still belong to its sentence.
+const sentenceClosers = `"')]}»”’」』）】`
+
+// Sentences splits text into sentences and returns their rune spans, trimmed
+// of surrounding whitespace. lang is the text's ISO 639-1 code and selects
+// the abbreviations that do not end a sentence. Besides . ! ? and …, line
+// breaks, CJK full stops (no space needed) and an emoji followed by a
+// capitalised word end a sentence.
+func Sentences(text, lang string) []Span {
+	rs := []rune(text)
+	emojiEnds := emojiRuneEnds(text)
+	var out []Span
+	start := 0
+	cut := func(end int) {
+		s, e := start, end
+		for s < e && unicode.IsSpace(rs[s]) {
+			s++
+		}
+		for e > s && unicode.IsSpace(rs[e-1]) {
+			e--
+		}
+		if e > s {
+			out = append(out, Span{Start: s, End: e})
+		}
+		start = end
+	}
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == '\n':
+			cut(i)
+		case isCJKSentenceEnd(r):
+			j := skipClosers(rs, skipTerminators(rs, i+1))
+			cut(j)
+			i = j - 1
+		case r == '.' || r == '!' || r == '?' || r == '…':
+			j := skipTerminators(rs, i+1)
+			end := skipClosers(rs, j)
+			if end < len(rs) && !unicode.IsSpace(rs[end]) {
+				// "3.5", "e.g.the", "example.com"
+				i = j - 1
+				continue
+			}
+			next := end
+			for next < len(rs) && rs[next] != '\n' && unicode.IsSpace(rs[next]) {
+				next++
+			}
+			lowerNext := next < len(rs) && unicode.IsLower(rs[next])
+			switch {
+			case j-i == 1 && r == '.' && isAbbreviation(rs, i, lang):
+			case lowerNext && (r == '…' || j-i > 1 && rs[i] == '.'):
+				// an ellipsis before a lowercase word trails off mid-sentence
+			default:
+				cut(end)
+			}
+			i = end - 1
+		case emojiEnds[i+1]:
+			next := i + 1
+			for next < len(rs) && rs[next] != '\n' && unicode.IsSpace(rs[next]) {
+				next++
+			}
+			if next > i+1 && next < len(rs) && unicode.IsUpper(rs[next]) {
+				cut(i + 1)
+			}
+		}
+	}
+	cut(len(rs))
+	return out
+}
+
+func skipTerminators(rs []rune, j int) int {
+	for j < len(rs) && (strings.ContainsRune(".!?…", rs[j]) || isCJKSentenceEnd(rs[j])) {
+		j++
+	}
+	return j
+}
+
+func skipClosers(rs []rune, j int) int {
+	for j < len(rs) && strings.ContainsRune(sentenceClosers, rs[j]) {
+		j++
+	}
+	return j
+}
+
+// isAbbreviation reports whether the period at rs[dot] ends a known
+// abbreviation or a single-letter initial such as the "J." in "J. Smith".
+// An initial needs a name after it, so "plan A. Then" still ends a sentence.
+func isAbbreviation(rs []rune, dot int, lang string) bool {
+	start := dot
+	for start > 0 && (unicode.IsLetter(rs[start-1]) || rs[start-1] == '.') {
+		start--
+	}
+	word := strings.ToLower(string(rs[start:dot]))
+	if word == "" {
+		return false
+	}
+	next := wordAfter(rs, dot+1)
+	if w := []rune(word); len(w) == 1 && unicode.IsUpper(rs[start]) {
+		return isName(next) || len(next) == 2 && next[1] == '.' && unicode.IsUpper(next[0])
+	}
+	if before, ok := wordAbbreviations[lang][word]; ok {
+		return before(next)
+	}
+	for _, a := range abbreviations[lang] {
+		if word == a {
+			return true
+		}
+	}
+	return false
+}
+
+// wordAfter returns the token that starts after the whitespace at rs[i:].
+func wordAfter(rs []rune, i int) []rune {
+	for i < len(rs) && unicode.IsSpace(rs[i]) {
+		i++
+	}
+	j := i
+	for j < len(rs) && !unicode.IsSpace(rs[j]) {
+		j++
+	}
+	return rs[i:j]
+}
+
+func startsWithDigit(w []rune) bool {
+	return len(w) > 0 && unicode.IsDigit(w[0])
+}
+
+// isName reports whether w is capitalized and, lowercased, not a common
+// English word, which keeps sentence starts such as "The" or "Then" out.
+func isName(w []rune) bool {
+	if len(w) == 0 || !unicode.IsUpper(w[0]) {
+		return false
+	}
+	_, common := englishWords[strings.ToLower(strings.TrimRightFunc(string(w), unicode.IsPunct))]
+	return !common
+}
+
+// emojiRuneEnds marks the rune offsets at which an emoji cluster ends.
+func emojiRuneEnds(text string) map[int]bool {
+	spans := findEmojis(text)
+	if len(spans) == 0 {
+		return nil
+	}
+	offsets := make([]int, 0, len(text))
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	ends := make(map[int]bool, len(spans))
+	for _, sp := range spans {
+		ends[sort.SearchInts(offsets, sp[1])] = true
+	}
+	return ends
+}
@@ -0,0 +1,42 @@
+package textutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		name, text, lang string
+		want             []string
+	}{
+		{"terminators", "Great app! Does it sync? Yes.", "en", []string{"Great app!", "Does it sync?", "Yes."}},
+		{"line breaks", "first line\nsecond line", "", []string{"first line", "second line"}},
+		{"decimal and host", "Version 3.5 on example.com works.", "en", []string{"Version 3.5 on example.com works."}},
+		{"title abbreviation", "Dr. Smith fixed it. Thanks.", "en", []string{"Dr. Smith fixed it.", "Thanks."}},
+		{"no as an answer", "Would I recommend it? No. The app crashes.", "en", []string{"Would I recommend it?", "No.", "The app crashes."}},
+		{"no before a number", "Ticket No. 5 is still open.", "en", []string{"Ticket No. 5 is still open."}},
+		{"st before a name", "We met in St. Louis last year.", "en", []string{"We met in St. Louis last year."}},
+		{"st ending a sentence", "The shop is on Main St. The staff are kind.", "en", []string{"The shop is on Main St.", "The staff are kind."}},
+		{"co is a word", "I asked the co. It said no.", "en", []string{"I asked the co.", "It said no."}},
+		{"max is a word", "Volume at max. Still too quiet.", "en", []string{"Volume at max.", "Still too quiet."}},
+		{"letter ending a sentence", "I use plan A. Then it broke.", "en", []string{"I use plan A.", "Then it broke."}},
+		{"initial before a name", "Written by J. Smith for us.", "en", []string{"Written by J. Smith for us."}},
+		{"chained initials", "A book by J. R. R. Tolkien, great.", "en", []string{"A book by J. R. R. Tolkien, great."}},
+		{"german abbreviation", "Z.B. die Suche. Sonst gut.", "de", []string{"Z.B. die Suche.", "Sonst gut."}},
+		{"ellipsis trails off", "well... it works. Mostly.", "en", []string{"well... it works.", "Mostly."}},
+		{"cjk full stops", "很好用。推荐！", "zh", []string{"很好用。", "推荐！"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := []rune(tt.text)
+			var got []string
+			for _, sp := range Sentences(tt.text, tt.lang) {
+				got = append(got, string(rs[sp.Start:sp.End]))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Sentences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
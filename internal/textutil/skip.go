@@ -0,0 +1,26 @@
+package textutil
+
+import "strings"
+
+// SkipReason records why a review was not treated as contentful.
+type SkipReason string
+
+const (
+	SkipNone               SkipReason = ""
+	SkipEmptyAfterCleaning SkipReason = "empty_after_cleaning"
+	SkipTooShort           SkipReason = "too_short"
+	SkipTooFewWords        SkipReason = "too_few_words"
+	SkipTooFewChars        SkipReason = "too_few_chars"
+	SkipLowAlphaRatio      SkipReason = "low_alpha_ratio"
+	SkipSpam               SkipReason = "spam"
+	SkipDuplicate          SkipReason = "duplicate"
+)
+
+// ShortReason explains why cleaned text failed the minimum length check:
+// nothing was left, or what was left is too short.
+func ShortReason(cleaned string) SkipReason {
+	if strings.TrimSpace(cleaned) == "" {
+		return SkipEmptyAfterCleaning
+	}
+	return SkipTooShort
+}
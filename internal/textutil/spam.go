@@ -0,0 +1,288 @@
+package textutil
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"unicode"
+)
+
+//go:embed wordlist_en.txt
+var wordlistEN string
+
+// englishWords holds common English words, including the vocabulary typical
+// of app reviews. It only has to cover enough running text to tell prose
+// from keyboard mashing, not to spell-check.
+var englishWords = func() map[string]struct{} {
+	words := strings.Fields(wordlistEN)
+	m := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		m[w] = struct{}{}
+	}
+	return m
+}()
+
+// Weights of the individual signals in SpamScore. They are combined as a
+// noisy-OR, so one strong signal can flag a text on its own and weaker ones
+// add up. The dictionary signal stays below the default spam_threshold on its
+// own: slang, brand names and misdetected languages miss the word list too.
+const (
+	spamWeightEntropy    = 0.6
+	spamWeightRepetition = 0.8
+	spamWeightGibberish  = 0.8
+	spamWeightDictionary = 0.5
+	spamWeightURLs       = 0.8
+)
+
+// SpamSignals are the components of a spam score, each within [0,1] where
+// higher is more suspicious.
+type SpamSignals struct {
+	// LowEntropy is set for text drawn from very few distinct letters.
+	LowEntropy float64
+	// Repetition covers stretched characters, repeated word sequences and
+	// substrings repeated within a word ("asdfasdf").
+	Repetition float64
+	// Gibberish is high when most longer words look like keyboard mashing in
+	// any language: no vowels, or mostly neighbouring keys ("qwerty").
+	Gibberish float64
+	// DictionaryMiss is high when almost no word of English text is a common
+	// English word.
+	DictionaryMiss float64
+	// URLDensity is the share of tokens that are links or [URL] placeholders.
+	URLDensity float64
+}
+
+// Score combines the signals into a single value within [0,1].
+func (s SpamSignals) Score() float64 {
+	keep := (1 - spamWeightEntropy*s.LowEntropy) *
+		(1 - spamWeightRepetition*s.Repetition) *
+		(1 - spamWeightGibberish*s.Gibberish) *
+		(1 - spamWeightDictionary*s.DictionaryMiss) *
+		(1 - spamWeightURLs*s.URLDensity)
+	return 1 - keep
+}
+
+// SpamScore rates how likely text is spam or gibberish, within [0,1]. lang is
+// the detected language, or empty when detection was not confident; the
+// dictionary signal only applies to text confidently detected as English.
+func SpamScore(text, lang string) float64 {
+	return Spam(text, lang).Score()
+}
+
+// Spam computes the individual spam signals for text; see SpamScore.
+func Spam(text, lang string) SpamSignals {
+	words := spamTokens(text)
+	return SpamSignals{
+		LowEntropy:     lowEntropy(text),
+		Repetition:     max(charRunRatio(text), repeatedTrigramRatio(words), intraWordRepetition(words)),
+		Gibberish:      gibberish(words),
+		DictionaryMiss: dictionaryMiss(words, lang),
+		URLDensity:     urlDensity(text, len(words)),
+	}
+}
+
+// spamTokens splits text into lowercased words of letters, digits and
+// apostrophes.
+func spamTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\'' && r != '’'
+	})
+}
+
+// lowEntropy maps the Shannon entropy of the letter distribution to [0,1]:
+// prose sits around 4 bits per letter, "hahahaha" at 1.
+func lowEntropy(text string) float64 {
+	counts := map[rune]int{}
+	total := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			counts[unicode.ToLower(r)]++
+			total++
+		}
+	}
+	// too short to tell a pattern from a short word
+	if total < 12 {
+		return 0
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return clamp01((2.5 - h) / 1.0)
+}
+
+// charRunRatio is the share of non-space characters that extend a run of the
+// same character past two ("soooo", "!!!!!").
+func charRunRatio(text string) float64 {
+	var prev rune
+	run, extra, total := 0, 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			prev, run = 0, 0
+			continue
+		}
+		total++
+		if r == prev {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		if run > 2 {
+			extra++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return clamp01(float64(extra) / float64(total) * 2)
+}
+
+// repeatedTrigramRatio is the share of word trigrams seen earlier in the
+// text. Prose rarely repeats three words in a row; pasted promo text and
+// "good good good good" do.
+func repeatedTrigramRatio(words []string) float64 {
+	if len(words) < 6 {
+		return 0
+	}
+	seen := make(map[[3]string]struct{}, len(words))
+	repeated := 0
+	for i := 0; i+3 <= len(words); i++ {
+		g := [3]string{words[i], words[i+1], words[i+2]}
+		if _, ok := seen[g]; ok {
+			repeated++
+		}
+		seen[g] = struct{}{}
+	}
+	return clamp01(float64(repeated) / float64(len(words)-2) * 1.5)
+}
+
+// intraWordRepetition measures how much of the long tokens consists of
+// repeated character trigrams, as in "asdfasdf" or "lolololol".
+func intraWordRepetition(words []string) float64 {
+	var repeated, total int
+	for _, w := range words {
+		rs := []rune(w)
+		if len(rs) < 6 {
+			continue
+		}
+		seen := make(map[string]struct{}, len(rs))
+		for i := 0; i+3 <= len(rs); i++ {
+			g := string(rs[i : i+3])
+			if _, ok := seen[g]; ok {
+				repeated++
+			}
+			seen[g] = struct{}{}
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return clamp01(float64(repeated) / float64(total) * 1.5)
+}
+
+// keyboardRows are the letter rows of a QWERTY keyboard.
+var keyboardRows = [...]string{"qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// vowels are the vowels of Latin-script alphabets, including y, which is one
+// in "rhythm" and "gym".
+const vowels = "aeiouyàáâãäåæèéêëěęìíîïòóôõöøőœùúûüűůýÿ"
+
+// gibberishMinLetters is the word length from which words are checked for
+// mashing; shorter words are too often acronyms or slang ("pls", "thx").
+const gibberishMinLetters = 5
+
+// gibberish rates the share of Latin words of at least gibberishMinLetters
+// letters that look mashed: they have no vowel, or at least three quarters
+// of their letter pairs are neighbouring keys on one keyboard row. Prose has
+// the occasional such word ("power"), so the signal starts at a quarter of
+// the words and saturates at three quarters. It does not depend on the
+// language, so it also covers text whose language was not detected.
+func gibberish(words []string) float64 {
+	var checked, mashed int
+	for _, w := range words {
+		rs := []rune(w)
+		if len(rs) < gibberishMinLetters || !isLatinWord(w) || strings.ContainsFunc(w, unicode.IsNumber) {
+			continue
+		}
+		checked++
+		if !strings.ContainsFunc(w, func(r rune) bool { return strings.ContainsRune(vowels, r) }) {
+			mashed++
+			continue
+		}
+		adjacent := 0
+		for i := 1; i < len(rs); i++ {
+			if neighbourKeys(rs[i-1], rs[i]) {
+				adjacent++
+			}
+		}
+		if float64(adjacent) >= 0.75*float64(len(rs)-1) {
+			mashed++
+		}
+	}
+	// too few words to tell mashing from a stray acronym or name
+	if checked < 3 {
+		return 0
+	}
+	return clamp01((float64(mashed)/float64(checked) - 0.25) / 0.5)
+}
+
+// neighbourKeys reports whether a and b are next to each other on one
+// keyboard row.
+func neighbourKeys(a, b rune) bool {
+	for _, row := range keyboardRows {
+		i, j := strings.IndexRune(row, a), strings.IndexRune(row, b)
+		if i >= 0 && j >= 0 && (i-j == 1 || j-i == 1) {
+			return true
+		}
+	}
+	return false
+}
+
+// dictionaryMiss is high when few words are common English words. Ordinary
+// English reviews hit the list for roughly half of their words. Other and
+// unknown languages miss it by nature, so they score 0; gibberish covers
+// mashing in those.
+func dictionaryMiss(words []string, lang string) float64 {
+	if lang != "en" {
+		return 0
+	}
+	var latin, hits int
+	for _, w := range words {
+		if !isLatinWord(w) {
+			continue
+		}
+		latin++
+		if _, ok := englishWords[strings.ReplaceAll(w, "’", "'")]; ok {
+			hits++
+		}
+	}
+	if latin < 4 {
+		return 0
+	}
+	return clamp01((0.3 - float64(hits)/float64(latin)) / 0.3)
+}
+
+func isLatinWord(w string) bool {
+	for _, r := range w {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// urlDensity relates links, raw or already redacted to [URL], to the number
+// of words; a quarter of the tokens being links saturates the signal.
+func urlDensity(text string, words int) float64 {
+	urls := len(reURL.FindAllStringIndex(text, -1)) + strings.Count(text, PIIURL.Placeholder())
+	if urls == 0 || words == 0 {
+		return 0
+	}
+	return clamp01(float64(urls) / float64(words) * 4)
+}
+
+func clamp01(v float64) float64 {
+	return max(0, min(1, v))
+}
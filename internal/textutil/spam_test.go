@@ -0,0 +1,83 @@
+package textutil
+
+import "testing"
+
+func TestSpamScore(t *testing.T) {
+	const threshold = 0.7 // spam_threshold in config.toml
+	tests := []struct {
+		name, text, lang string
+		spam             bool
+	}{
+		{"english review", "Great app, I use it every day and it works well", "en", false},
+		{"portuguese at low confidence", "Muito bom aplicativo, recomendo para todos", "", false},
+		{"portuguese as best guess", "Muito bom aplicativo, recomendo para todos", "pt", false},
+		{"german", "Die App stürzt ständig ab, bitte reparieren", "de", false},
+		{"english slang alone", "lit af fam ngl tbh vibes", "en", false},
+		{"keyboard mashing", "asdfasdfasdf qwerqwerqwer asdfasdfasdf", "", true},
+		{"gibberish of unknown language", "sdkfjh wqeoiru zxcmvn lkjqwe", "", true},
+		{"repeated mashing misdetected as english", "asdfasdf asdfasdf asdfasdf asdfasdf", "en", true},
+		{"keyboard rows", "qwertyuiop asdfghjkl zxcvbnm qwerty", "", true},
+		{"english with keyboard-row words", "Power users were there, pretty property app", "en", false},
+		{"laughter", "hahahahahahahahahahahaha", "", true},
+		{"stretched", "goooooooooooood aaaaaaaapppppp", "en", true},
+		{"repeated phrase", "best app ever best app ever best app ever best app ever", "en", true},
+		{"links", "free coins www.example.com [URL] [URL]", "en", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpamScore(tt.text, tt.lang)
+			if (got > threshold) != tt.spam {
+				t.Errorf("SpamScore(%q, %q) = %.2f, want spam=%v", tt.text, tt.lang, got, tt.spam)
+			}
+		})
+	}
+}
+
+func TestGibberish(t *testing.T) {
+	tests := []struct {
+		name, text string
+		want       float64
+	}{
+		{"english prose", "the application crashes whenever i upload photos", 0},
+		{"polish prose", "aplikacja działa świetnie, polecam wszystkim znajomym", 0},
+		{"no vowels", "sdkfjh zxcmvn brrrt", 1},
+		{"neighbouring keys", "qwerty asdfg zxcvb", 1},
+		{"one mashed word in four", "great value asdfgh really", 0},
+		{"too few words", "asdfgh qwerty", 0},
+		{"numbers are not words", "order 12345 67890 55555", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gibberish(spamTokens(tt.text)); got != tt.want {
+				t.Errorf("gibberish(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictionaryMiss(t *testing.T) {
+	tests := []struct {
+		name, text, lang string
+		want             float64
+	}{
+		{"english prose", "the app is good and i like it", "en", 0},
+		{"unknown words", "zorp blint quaz frum", "en", 1},
+		{"unknown language", "zorp blint quaz frum", "", 0},
+		{"other language", "muito bom aplicativo recomendo", "pt", 0},
+		{"too few words", "zorp blint quaz", "en", 0},
+		{"non-latin words ignored", "отличное приложение всем советую", "en", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dictionaryMiss(spamTokens(tt.text), tt.lang); got != tt.want {
+				t.Errorf("dictionaryMiss(%q, %q) = %v, want %v", tt.text, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictionarySignalAloneStaysBelowThreshold(t *testing.T) {
+	if got := (SpamSignals{DictionaryMiss: 1}).Score(); got >= 0.7 {
+		t.Errorf("dictionary signal alone scores %.2f", got)
+	}
+}
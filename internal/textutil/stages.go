@@ -0,0 +1,206 @@
+package textutil
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// Built-in stage names, as used in [[processing.pipeline]].
+const (
+	StageHTML       = "html"
+	StageNormalize  = "normalize"
+	StageEmoji      = "emoji"
+	StageEmphasis   = "emphasis"
+	StagePII        = "pii"
+	StageWhitespace = "whitespace"
+	StageTruncate   = "truncate"
+)
+
+var stageBuilders = map[string]func(p stageParams) (Stage, error){
+	StageHTML:       buildHTMLStage,
+	StageNormalize:  buildNormalizeStage,
+	StageEmoji:      buildEmojiStage,
+	StageEmphasis:   buildEmphasisStage,
+	StagePII:        buildPIIStage,
+	StageWhitespace: buildWhitespaceStage,
+	StageTruncate:   buildTruncateStage,
+}
+
+// HTMLStage strips markup; see StripHTML.
+type HTMLStage struct{}
+
+func (HTMLStage) Name() string { return StageHTML }
+
+func (HTMLStage) Apply(d *Doc) { d.Text = StripHTML(d.Text) }
+
+func buildHTMLStage(p stageParams) (Stage, error) {
+	return HTMLStage{}, p.only()
+}
+
+// NormalizeStage applies Unicode normalization; see Normalize.
+type NormalizeStage struct {
+	Options NormalizeOptions
+}
+
+func (NormalizeStage) Name() string { return StageNormalize }
+
+func (s NormalizeStage) Apply(d *Doc) { d.Text = Normalize(d.Text, s.Options) }
+
+// Params: form (none | NFC | NFKC), strip_invisible, fold_confusables.
+func buildNormalizeStage(p stageParams) (Stage, error) {
+	if err := p.only("form", "strip_invisible", "fold_confusables"); err != nil {
+		return nil, err
+	}
+	name, err := p.string("form", string(NormNFC))
+	if err != nil {
+		return nil, err
+	}
+	form, err := ParseNormForm(name)
+	if err != nil {
+		return nil, err
+	}
+	invisible, err := p.bool("strip_invisible", false)
+	if err != nil {
+		return nil, err
+	}
+	confusables, err := p.bool("fold_confusables", false)
+	if err != nil {
+		return nil, err
+	}
+	return NormalizeStage{Options: NormalizeOptions{Form: form, StripInvisible: invisible, FoldConfusables: confusables}}, nil
+}
+
+// EmojiStage applies an EmojiMode. In extract mode the removed emoji are
+// collected in Doc.Emojis.
+type EmojiStage struct {
+	Mode EmojiMode
+}
+
+func (EmojiStage) Name() string { return StageEmoji }
+
+func (s EmojiStage) Apply(d *Doc) {
+	text, found := ApplyEmojiMode(d.Text, s.Mode)
+	d.Text = text
+	if s.Mode == EmojiExtract {
+		d.Emojis = append(d.Emojis, found...)
+	}
+}
+
+// Params: mode (keep | strip | shortcode | extract).
+func buildEmojiStage(p stageParams) (Stage, error) {
+	if err := p.only("mode"); err != nil {
+		return nil, err
+	}
+	name, err := p.string("mode", string(EmojiKeep))
+	if err != nil {
+		return nil, err
+	}
+	mode, err := ParseEmojiMode(name)
+	if err != nil {
+		return nil, err
+	}
+	return EmojiStage{Mode: mode}, nil
+}
+
+// EmphasisStage tones down elongation, repeated punctuation and optionally
+// shouting, and records what it found in Doc.Emphasis; see NormalizeEmphasis.
+type EmphasisStage struct {
+	LowercaseShouting bool
+}
+
+func (EmphasisStage) Name() string { return StageEmphasis }
+
+func (s EmphasisStage) Apply(d *Doc) {
+	text, em := NormalizeEmphasis(d.Text, s.LowercaseShouting)
+	d.Text = text
+	d.Emphasis = &em
+}
+
+// Params: lowercase_shouting.
+func buildEmphasisStage(p stageParams) (Stage, error) {
+	if err := p.only("lowercase_shouting"); err != nil {
+		return nil, err
+	}
+	lower, err := p.bool("lowercase_shouting", false)
+	if err != nil {
+		return nil, err
+	}
+	return EmphasisStage{LowercaseShouting: lower}, nil
+}
+
+// PIIStage redacts personal data using Doc.Locale and records the types found
+// in Doc.PII.
+type PIIStage struct {
+	Redactor *Redactor
+}
+
+func (PIIStage) Name() string { return StagePII }
+
+func (s PIIStage) Apply(d *Doc) {
+	text, types := s.Redactor.Redact(d.Text, d.Locale)
+	d.Text = text
+	for _, t := range types {
+		if !slices.Contains(d.PII, t) {
+			d.PII = append(d.PII, t)
+		}
+	}
+}
+
+// Params: detectors, in priority order; defaults to all of them.
+func buildPIIStage(p stageParams) (Stage, error) {
+	if err := p.only("detectors"); err != nil {
+		return nil, err
+	}
+	names, err := p.strings("detectors", []string{"email", "url", "card", "phone", "order_id"})
+	if err != nil {
+		return nil, err
+	}
+	detectors, err := DetectorsByName(names)
+	if err != nil {
+		return nil, err
+	}
+	return PIIStage{Redactor: NewRedactor(detectors...)}, nil
+}
+
+// WhitespaceStage collapses whitespace; see NormalizeWhitespace.
+type WhitespaceStage struct{}
+
+func (WhitespaceStage) Name() string { return StageWhitespace }
+
+func (WhitespaceStage) Apply(d *Doc) { d.Text = NormalizeWhitespace(d.Text) }
+
+func buildWhitespaceStage(p stageParams) (Stage, error) {
+	return WhitespaceStage{}, p.only()
+}
+
+// TruncateStage cuts the text to MaxLen runes; see Truncate.
+type TruncateStage struct {
+	MaxLen int
+}
+
+func (TruncateStage) Name() string { return StageTruncate }
+
+func (s TruncateStage) Apply(d *Doc) {
+	n := utf8.RuneCountInString(d.Text)
+	text, cut := Truncate(d.Text, s.MaxLen)
+	if cut && !d.Truncated {
+		d.Truncated, d.OriginalLen = true, n
+	}
+	d.Text = text
+}
+
+// Params: max_len, in runes; 0 disables the cut.
+func buildTruncateStage(p stageParams) (Stage, error) {
+	if err := p.only("max_len"); err != nil {
+		return nil, err
+	}
+	n, err := p.int("max_len", 0)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("max_len must be >= 0, got %d", n)
+	}
+	return TruncateStage{MaxLen: n}, nil
+}
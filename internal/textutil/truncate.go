@@ -0,0 +1,122 @@
+package textutil
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// truncateLookback is the share of the allowed length searched backwards for
+// a sentence or word boundary before falling back to a hard cut.
+const truncateLookback = 0.2
+
+// Truncate shortens s to at most maxRunes runes and reports whether it did.
+// It prefers to cut after the last sentence end, then at the last word break
+// within the final part of the allowed text, and never splits a multi-byte
+// character, an emoji cluster or a base letter from its combining marks.
+func Truncate(s string, maxRunes int) (string, bool) {
+	if maxRunes <= 0 || utf8.RuneCountInString(s) <= maxRunes {
+		return s, false
+	}
+
+	// byte offset just past the maxRunes-th rune
+	limit := 0
+	for n := 0; n < maxRunes; n++ {
+		_, size := utf8.DecodeRuneInString(s[limit:])
+		limit += size
+	}
+	limit = clusterStart(s, limit)
+
+	floor := limit - int(float64(limit)*truncateLookback)
+	sentence, word := -1, -1
+	prev := rune(0)
+	for i, r := range s[:limit] {
+		if i >= floor {
+			if unicode.IsSpace(r) {
+				word = i
+				if isSentenceEnd(prev) {
+					sentence = i
+				}
+			} else if isCJKSentenceEnd(r) {
+				sentence = i + utf8.RuneLen(r)
+			}
+		}
+		prev = r
+	}
+	// a sentence end exactly at the limit
+	if r, _ := utf8.DecodeRuneInString(s[limit:]); unicode.IsSpace(r) || r == utf8.RuneError {
+		if last, _ := utf8.DecodeLastRuneInString(s[:limit]); isSentenceEnd(last) || isCJKSentenceEnd(last) {
+			sentence = limit
+		}
+	}
+
+	cut := limit
+	switch {
+	case sentence > 0:
+		cut = sentence
+	case word > 0:
+		cut = word
+	}
+	return trimRightSpace(s[:cut]), true
+}
+
+// clusterStart moves a cut point at byte offset i back so that it does not
+// fall inside an emoji cluster or before a combining mark.
+func clusterStart(s string, i int) int {
+	for i > 0 && i < len(s) {
+		r, _ := utf8.DecodeRuneInString(s[i:])
+		if !unicode.Is(unicode.M, r) && r != zwj && r != vs16 && r != vs15 && !isSkinTone(r) && r != keycapCombiner && !(r >= 0xE0020 && r <= tagEnd) && !isRegionalPairTail(s, i) {
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+	}
+	// a ZWJ sequence continues after the joiner; back off to the start of the element
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		if r != zwj {
+			break
+		}
+		i -= size
+		_, size = utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+	}
+	return i
+}
+
+// isRegionalPairTail reports whether the rune at i is the second regional
+// indicator of a flag.
+func isRegionalPairTail(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	if !isRegionalIndicator(r) {
+		return false
+	}
+	n := 0
+	for j := i; j > 0; {
+		p, size := utf8.DecodeLastRuneInString(s[:j])
+		if !isRegionalIndicator(p) {
+			break
+		}
+		n++
+		j -= size
+	}
+	return n%2 == 1
+}
+
+func isSentenceEnd(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+func isCJKSentenceEnd(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+func trimRightSpace(s string) string {
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if !unicode.IsSpace(r) {
+			break
+		}
+		s = s[:len(s)-size]
+	}
+	return s
+}
@@ -0,0 +1,33 @@
+package textutil
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		max      int
+		want     string
+		wantTrim bool
+	}{
+		{"short enough", "Nice app.", 20, "Nice app.", false},
+		{"limit disabled", "Nice app.", 0, "Nice app.", false},
+		{"sentence boundary", "Good app, works well. Crashes a lot", 22, "Good app, works well.", true},
+		{"sentence end at limit", "Love it. Hate ads.", 8, "Love it.", true},
+		{"word boundary", "This app is really good and very fast", 25, "This app is really good", true},
+		{"hard cut without boundary", "Supercalifragilistic", 10, "Supercalif", true},
+		{"cjk sentence end", "这个应用非常好用真的。但是广告太多了", 12, "这个应用非常好用真的。", true},
+		{"boundary outside lookback", "Short. Then a long run of words", 25, "Short. Then a long run", true},
+		{"emoji cluster kept whole", "ok 👍🏽", 4, "ok", true},
+		{"flag kept whole", "abcdefghi 🇩🇪", 11, "abcdefghi", true},
+		{"combining mark kept", "cafés", 4, "caf", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, trimmed := Truncate(tt.in, tt.max)
+			if got != tt.want || trimmed != tt.wantTrim {
+				t.Errorf("Truncate(%q, %d) = %q, %v; want %q, %v", tt.in, tt.max, got, trimmed, tt.want, tt.wantTrim)
+			}
+		})
+	}
+}
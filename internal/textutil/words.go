@@ -0,0 +1,135 @@
+package textutil
+
+import (
+	"math"
+	"unicode"
+)
+
+// latinCharsPerWord is the typical word length of space-delimited text; it
+// converts the other scripts' word lengths into comparable character counts.
+const latinCharsPerWord = 5
+
+// denseScripts are written without spaces between words. charsPerWord is the
+// typical number of characters a word takes in that script.
+var denseScripts = []struct {
+	table        *unicode.RangeTable
+	charsPerWord float64
+}{
+	{unicode.Han, 2},
+	{unicode.Hiragana, 3},
+	{unicode.Katakana, 3},
+	{unicode.Thai, 5},
+	{unicode.Lao, 5},
+	{unicode.Khmer, 5},
+	{unicode.Myanmar, 5},
+}
+
+// charsPerWord returns the typical word length of r's script when that
+// script does not separate words with spaces, or 0 otherwise.
+func charsPerWord(r rune) float64 {
+	if r < 0x0E00 {
+		return 0
+	}
+	for _, s := range denseScripts {
+		if unicode.Is(s.table, r) {
+			return s.charsPerWord
+		}
+	}
+	return 0
+}
+
+// hangulSyllableWeight is how many Latin characters a Hangul syllable block
+// is worth; Korean separates words with spaces but packs 2-3 letters per block.
+const hangulSyllableWeight = 2
+
+// isWordConnector reports whether r may join two parts of one word, as in
+// "don't" or "e-mail".
+func isWordConnector(r rune) bool {
+	switch r {
+	case '\'', '’', '-', '‐', '_':
+		return true
+	}
+	return false
+}
+
+// CountWords estimates the number of words in text. Space-delimited scripts
+// (Latin, Cyrillic, Hangul, ...) are split on whitespace and punctuation; runs
+// of scripts written without spaces (Han, kana, Thai, ...) count as their
+// length divided by the script's typical word length, rounded up.
+func CountWords(text string) int {
+	words := 0
+	inWord := false
+	var dense float64
+	flush := func() {
+		if inWord {
+			words++
+			inWord = false
+		}
+		if dense > 0 {
+			words += int(math.Ceil(dense))
+			dense = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsMark(r):
+			// combining marks (Thai vowels, accents) belong to the preceding letter
+		case inWord && isWordConnector(r):
+		case charsPerWord(r) > 0:
+			if inWord {
+				words++
+				inWord = false
+			}
+			dense += 1 / charsPerWord(r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if dense > 0 {
+				flush()
+			}
+			inWord = true
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// TextLength measures text in Latin-equivalent characters: whitespace is not
+// counted, and a character of a script written without spaces counts as much
+// as the Latin characters it typically replaces (a Han character as 2.5, a
+// kana as about 1.7, a Hangul syllable as 2), so one threshold works across
+// scripts.
+func TextLength(text string) int {
+	var n float64
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+		case charsPerWord(r) > 0:
+			n += latinCharsPerWord / charsPerWord(r)
+		case r >= 0xAC00 && r <= 0xD7A3: // Hangul syllable blocks
+			n += hangulSyllableWeight
+		default:
+			n++
+		}
+	}
+	return int(math.Round(n))
+}
+
+// AlphaRatio is the share of letters, digits and combining marks among the
+// non-space characters of text; it is 0 for text without any.
+func AlphaRatio(text string) float64 {
+	var alpha, total int
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r) {
+			alpha++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(alpha) / float64(total)
+}
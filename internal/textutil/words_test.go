@@ -0,0 +1,69 @@
+package textutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 0},
+		{"latin", "Great app, works well!", 4},
+		{"connectors", "don't re-install the e-mail app", 5},
+		{"numbers", "5 stars for v2", 4},
+		{"cyrillic", "Отличное приложение, спасибо", 3},
+		{"hangul", "정말 좋은 앱입니다", 3},
+		{"han", "这个应用很好用", 4},                   // 7 / 2 rounded up
+		{"kana", "とてもいいです", 3},                  // 7 / 3 rounded up
+		{"mixed japanese", "このアプリは最高", 3},       // 5 kana / 3 + 2 han / 2
+		{"thai with marks", "ใช้งานง่ายมาก", 3}, // 11 letters / 5, marks not counted
+		{"dense then latin", "很好 app", 2},
+		{"latin then dense", "app很好", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountWords(tt.in); got != tt.want {
+				t.Errorf("CountWords(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextLength(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"good app", 7},
+		{"很好", 5},
+		{"いい", 3},
+		{"좋아요", 6},
+	}
+	for _, tt := range tests {
+		if got := TextLength(tt.in); got != tt.want {
+			t.Errorf("TextLength(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAlphaRatio(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"", 0},
+		{"   ", 0},
+		{"abc", 1},
+		{"ab!!", 0.5},
+		{"ใช้", 1},
+	}
+	for _, tt := range tests {
+		if got := AlphaRatio(tt.in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("AlphaRatio(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -25,7 +25,12 @@ func (c *Cascade) TranslateBatch(ctx context.Context, items []Item, target strin
 	if err != nil {
 		// On request/limit/error – use fallback if configured
 		if c.Fallback != nil {
-			return c.Fallback.TranslateBatch(ctx, items, target)
+			fb, fbErr := c.Fallback.TranslateBatch(ctx, items, target)
+			for id, r := range fb {
+				r.Fallback = true
+				fb[id] = r
+			}
+			return fb, fbErr
 		}
 		return res, err
 	}
@@ -61,6 +66,7 @@ func (c *Cascade) TranslateBatch(ctx context.Context, items []Item, target strin
 		}
 		// prefer fallback if it improved adequacy or produced non-empty when primary was empty
 		if pr.Translated == "" || !isPoorAdequacy(it.Text, fr.Translated, c.AdequacyRatio) {
+			fr.Fallback = true
 			res[it.ID] = fr
 		}
 	}
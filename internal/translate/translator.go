@@ -11,6 +11,8 @@ type Result struct {
 	ID         string `json:"id"`
 	Lang       string `json:"lang"`
 	Translated string `json:"translated"`
+	// Fallback is set by Cascade when the result came from the fallback translator.
+	Fallback bool `json:"-"`
 }
 
 type Translator interface {